@@ -0,0 +1,207 @@
+// Package balancer implements a client side load balancer.
+package balancer
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"github.com/andy2046/gopie/pkg/breaker"
+	"github.com/andy2046/gopie/pkg/subset"
+)
+
+type (
+	// Backend is the backend in the Balancer.
+	Backend struct {
+		// Name is the name of the backend, e.g. host:port.
+		Name string
+
+		inflight int64
+		cb       *breaker.CircuitBreaker
+	}
+
+	// Picker picks a backend from the healthy backends.
+	Picker interface {
+		// Pick returns one of the given backends for key,
+		// backends is not empty and is ordered by name.
+		Pick(key string, backends []*Backend) (*Backend, error)
+	}
+
+	// Balancer picks a healthy backend from a subset of backends for each request.
+	Balancer struct {
+		picker         Picker
+		subset         *subset.Dynamic
+		clientID       int
+		breakerOptions []breaker.Option
+
+		mu       sync.RWMutex
+		backends map[string]*Backend
+		// inUse is the subset of backends ordered by name.
+		inUse []*Backend
+	}
+
+	// Config is the config for Balancer.
+	Config struct {
+		// Picker picks a backend for each request.
+		// If Picker is nil, RoundRobin is used.
+		Picker Picker
+		// SubsetSize is the number of backends used by this client.
+		// If SubsetSize is 0, all the backends are used.
+		SubsetSize int
+		// ClientID is the id of this client for subsetting.
+		ClientID int
+		// BreakerOptions are applied to the CircuitBreaker of each backend,
+		// a backend is ejected while its CircuitBreaker is open.
+		BreakerOptions []breaker.Option
+	}
+
+	// Option applies config to Config.
+	Option = func(*Config) error
+)
+
+var (
+	// ErrNoBackend when there is no healthy backend.
+	ErrNoBackend = errors.New("no healthy backend")
+	// DefaultConfig is the default config for Balancer.
+	DefaultConfig = Config{
+		SubsetSize: 0,
+		ClientID:   0,
+	}
+)
+
+// New returns a new Balancer with the given backends.
+func New(backends []string, options ...Option) *Balancer {
+	c := DefaultConfig
+	err := setOption(&c, options...)
+	if err != nil {
+		log.Panicf("fail to apply Config -> %v\n", err)
+	}
+	if c.Picker == nil {
+		c.Picker = RoundRobin()
+	}
+
+	b := &Balancer{
+		picker:         c.Picker,
+		clientID:       c.ClientID,
+		breakerOptions: c.BreakerOptions,
+		backends:       map[string]*Backend{},
+	}
+	if c.SubsetSize > 0 {
+		b.subset = subset.NewDynamic(c.SubsetSize)
+	}
+	b.SetBackends(backends...)
+	return b
+}
+
+// InFlight returns the number of requests in flight to the backend.
+func (be *Backend) InFlight() int64 {
+	return atomic.LoadInt64(&be.inflight)
+}
+
+// Healthy returns false if the CircuitBreaker of the backend is open.
+func (be *Backend) Healthy() bool {
+	return be.cb.State() != breaker.StateOpen
+}
+
+// SetBackends replaces all the backends,
+// the state of the backends already in the Balancer is kept.
+func (b *Balancer) SetBackends(backends ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	names := backends
+	if b.subset != nil {
+		keep := make(map[string]bool, len(backends))
+		for _, name := range backends {
+			keep[name] = true
+		}
+		for _, name := range b.subset.Backends() {
+			if !keep[name] {
+				b.subset.RemoveBackend(name)
+			}
+		}
+		for _, name := range backends {
+			b.subset.AddBackend(subset.Backend{Name: name, Weight: 1})
+		}
+		names = b.subset.Subset(b.clientID)
+	}
+
+	inUse := make(map[string]*Backend, len(names))
+	for _, name := range names {
+		be, ok := b.backends[name]
+		if !ok {
+			be = &Backend{Name: name, cb: b.newBreaker(name)}
+		}
+		inUse[name] = be
+	}
+	b.backends = inUse
+
+	b.inUse = make([]*Backend, 0, len(inUse))
+	for _, be := range inUse {
+		b.inUse = append(b.inUse, be)
+	}
+	sortBackends(b.inUse)
+}
+
+// Backends returns the names of the backends in use.
+func (b *Balancer) Backends() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	names := make([]string, len(b.inUse))
+	for i, be := range b.inUse {
+		names[i] = be.Name
+	}
+	return names
+}
+
+// Pick returns a healthy backend for key.
+func (b *Balancer) Pick(key string) (*Backend, error) {
+	b.mu.RLock()
+	healthy := make([]*Backend, 0, len(b.inUse))
+	for _, be := range b.inUse {
+		if be.Healthy() {
+			healthy = append(healthy, be)
+		}
+	}
+	b.mu.RUnlock()
+
+	if len(healthy) == 0 {
+		return nil, ErrNoBackend
+	}
+	return b.picker.Pick(key, healthy)
+}
+
+// Do runs the given request against a healthy backend for key,
+// the result of the request is reported to the CircuitBreaker of the backend.
+func (b *Balancer) Do(key string, request func(backend string) (interface{}, error)) (interface{}, error) {
+	be, err := b.Pick(key)
+	if err != nil {
+		return nil, err
+	}
+
+	atomic.AddInt64(&be.inflight, 1)
+	defer atomic.AddInt64(&be.inflight, -1)
+
+	return be.cb.Execute(func() (interface{}, error) {
+		return request(be.Name)
+	})
+}
+
+func (b *Balancer) newBreaker(name string) *breaker.CircuitBreaker {
+	options := append([]breaker.Option{func(s *breaker.Settings) error {
+		s.Name = name
+		return nil
+	}}, b.breakerOptions...)
+	return breaker.New(options...)
+}
+
+func setOption(c *Config, options ...func(*Config) error) error {
+	for _, opt := range options {
+		if err := opt(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
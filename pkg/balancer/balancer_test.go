@@ -0,0 +1,234 @@
+package balancer
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andy2046/gopie/pkg/breaker"
+)
+
+var errFail = errors.New("fail")
+
+func names(n int) []string {
+	backends := make([]string, n)
+	for i := range backends {
+		backends[i] = "10.0.0." + strconv.Itoa(i) + ":80"
+	}
+	return backends
+}
+
+func pick(t *testing.T, b *Balancer, key string) string {
+	be, err := b.Pick(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return be.Name
+}
+
+func TestNoBackend(t *testing.T) {
+	b := New(nil)
+	if _, err := b.Pick(""); err != ErrNoBackend {
+		t.Fatalf("expected ErrNoBackend, got %v", err)
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	b := New(names(3))
+	counts := map[string]int{}
+	for i := 0; i < 30; i++ {
+		counts[pick(t, b, "")]++
+	}
+	for k, v := range counts {
+		if v != 10 {
+			t.Fatalf("backend %s picked %d times, expected 10", k, v)
+		}
+	}
+}
+
+func TestPowerOfTwo(t *testing.T) {
+	b := New(names(2), func(c *Config) error {
+		c.Picker = PowerOfTwo()
+		return nil
+	})
+	busy, _ := b.Pick("")
+	busy.inflight = 10
+
+	for i := 0; i < 10; i++ {
+		if n := pick(t, b, ""); n == busy.Name {
+			t.Fatalf("busy backend %s should not be picked", n)
+		}
+	}
+}
+
+func TestLeastLoaded(t *testing.T) {
+	b := New(names(3), func(c *Config) error {
+		c.Picker = LeastLoaded()
+		return nil
+	})
+
+	for i := 0; i < 30; i++ {
+		be, err := b.Pick("key")
+		if err != nil {
+			t.Fatal(err)
+		}
+		be.inflight++
+	}
+	for _, be := range b.inUse {
+		if be.inflight > 13 {
+			t.Fatalf("backend %s is overloaded with %d requests", be.Name, be.inflight)
+		}
+	}
+}
+
+func TestConsistentHash(t *testing.T) {
+	b := New(names(5), func(c *Config) error {
+		c.Picker = ConsistentHash()
+		return nil
+	})
+
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		if pick(t, b, key) != pick(t, b, key) {
+			t.Fatalf("inconsistent backend for key %s", key)
+		}
+	}
+}
+
+func TestSubset(t *testing.T) {
+	b := New(names(10), func(c *Config) error {
+		c.SubsetSize = 3
+		c.ClientID = 7
+		return nil
+	})
+	inUse := b.Backends()
+	if len(inUse) != 3 {
+		t.Fatalf("expected 3 backends in use, got %v", inUse)
+	}
+
+	b.SetBackends(append(names(10), "10.0.1.0:80")...)
+	before := map[string]bool{}
+	for _, n := range inUse {
+		before[n] = true
+	}
+	changed := 0
+	for _, n := range b.Backends() {
+		if !before[n] {
+			changed++
+		}
+	}
+	if changed > 1 {
+		t.Fatalf("subset changed from %v to %v", inUse, b.Backends())
+	}
+}
+
+func TestEject(t *testing.T) {
+	b := New(names(2), func(c *Config) error {
+		c.BreakerOptions = []breaker.Option{func(s *breaker.Settings) error {
+			s.ShouldTrip = func(counts breaker.Counts) bool {
+				return counts.ConsecutiveFailures >= 2
+			}
+			s.Timeout = time.Minute
+			return nil
+		}}
+		return nil
+	})
+	bad := "10.0.0.1:80"
+
+	for i := 0; i < 10; i++ {
+		b.Do("", func(backend string) (interface{}, error) {
+			if backend == bad {
+				return nil, errFail
+			}
+			return nil, nil
+		})
+	}
+
+	for i := 0; i < 10; i++ {
+		if n := pick(t, b, ""); n == bad {
+			t.Fatalf("unhealthy backend %s should be ejected", n)
+		}
+	}
+}
+
+func TestRoundTripper(t *testing.T) {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	handler := func(name string, status int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hits[name]++
+			mu.Unlock()
+			w.WriteHeader(status)
+		}
+	}
+
+	good := httptest.NewServer(handler("good", http.StatusOK))
+	defer good.Close()
+	bad := httptest.NewServer(handler("bad", http.StatusServiceUnavailable))
+	defer bad.Close()
+
+	host := func(s *httptest.Server) string {
+		u, _ := url.Parse(s.URL)
+		return u.Host
+	}
+
+	b := New([]string{host(good), host(bad)})
+	client := &http.Client{Transport: b.RoundTripper(nil, nil)}
+
+	statuses := map[int]int{}
+	for i := 0; i < 30; i++ {
+		resp, err := client.Get("http://service/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		statuses[resp.StatusCode]++
+	}
+
+	// the default CircuitBreaker trips after 6 consecutive failures.
+	if statuses[http.StatusServiceUnavailable] != 6 {
+		t.Fatalf("expected 6 failed responses before ejection, got %v", statuses)
+	}
+	if hits["bad"] != 6 || hits["good"] != 24 {
+		t.Fatalf("unexpected hits %v", hits)
+	}
+}
+
+func TestRoundTripperInFlight(t *testing.T) {
+	release := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-release
+	}))
+	defer s.Close()
+
+	u, _ := url.Parse(s.URL)
+	b := New([]string{u.Host})
+	client := &http.Client{Transport: b.RoundTripper(nil, nil)}
+
+	resp, err := client.Get("http://service/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the request is in flight while the body is being streamed.
+	be := b.backends[u.Host]
+	if n := be.InFlight(); n != 1 {
+		t.Fatalf("expected 1 request in flight before the body is closed, got %d", n)
+	}
+	close(release)
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body.Close()
+	if n := be.InFlight(); n != 0 {
+		t.Fatalf("expected no request in flight after the body is closed, got %d", n)
+	}
+}
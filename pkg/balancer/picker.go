@@ -0,0 +1,137 @@
+package balancer
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/andy2046/gopie/pkg/ringhash"
+)
+
+type (
+	roundRobin struct {
+		next uint64
+	}
+
+	powerOfTwo struct {
+		mu  sync.Mutex
+		rnd *rand.Rand
+	}
+
+	// ringPicker keeps the ring in sync with the healthy backends.
+	ringPicker struct {
+		least   bool
+		options []ringhash.Option
+
+		mu      sync.Mutex
+		ring    *ringhash.Ring
+		members map[string]bool
+	}
+)
+
+// RoundRobin returns a Picker which picks the backends in turn.
+func RoundRobin() Picker {
+	return &roundRobin{}
+}
+
+// PowerOfTwo returns a Picker which picks two backends randomly
+// and chooses the one with less requests in flight.
+func PowerOfTwo() Picker {
+	return &powerOfTwo{
+		rnd: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// LeastLoaded returns a Picker which uses consistent hashing with bounded loads,
+// the load of a backend is the number of requests in flight.
+func LeastLoaded(options ...ringhash.Option) Picker {
+	return &ringPicker{least: true, options: options}
+}
+
+// ConsistentHash returns a Picker which picks the backend closest to key in the hash ring.
+func ConsistentHash(options ...ringhash.Option) Picker {
+	return &ringPicker{options: options}
+}
+
+func (p *roundRobin) Pick(_ string, backends []*Backend) (*Backend, error) {
+	n := atomic.AddUint64(&p.next, 1) - 1
+	return backends[n%uint64(len(backends))], nil
+}
+
+func (p *powerOfTwo) Pick(_ string, backends []*Backend) (*Backend, error) {
+	if len(backends) == 1 {
+		return backends[0], nil
+	}
+
+	p.mu.Lock()
+	i := p.rnd.Intn(len(backends))
+	j := p.rnd.Intn(len(backends) - 1)
+	p.mu.Unlock()
+
+	if j >= i {
+		j++
+	}
+	if backends[j].InFlight() < backends[i].InFlight() {
+		return backends[j], nil
+	}
+	return backends[i], nil
+}
+
+func (p *ringPicker) Pick(key string, backends []*Backend) (*Backend, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sync(backends)
+
+	var (
+		name string
+		err  error
+	)
+	if p.least {
+		for _, be := range backends {
+			p.ring.UpdateLoad(be.Name, be.InFlight())
+		}
+		name, err = p.ring.GetLeastNode(key)
+	} else {
+		name, err = p.ring.GetNode(key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	i := sort.Search(len(backends), func(i int) bool {
+		return backends[i].Name >= name
+	})
+	return backends[i], nil
+}
+
+// sync rebuilds the ring if the backends changed.
+func (p *ringPicker) sync(backends []*Backend) {
+	if p.ring != nil && len(p.members) == len(backends) {
+		same := true
+		for _, be := range backends {
+			if !p.members[be.Name] {
+				same = false
+				break
+			}
+		}
+		if same {
+			return
+		}
+	}
+
+	p.ring = ringhash.New(p.options...)
+	p.members = make(map[string]bool, len(backends))
+	for _, be := range backends {
+		p.ring.AddNode(be.Name)
+		p.members[be.Name] = true
+	}
+}
+
+func sortBackends(backends []*Backend) {
+	sort.Slice(backends, func(i, j int) bool {
+		return backends[i].Name < backends[j].Name
+	})
+}
@@ -0,0 +1,89 @@
+package balancer
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	roundTripper struct {
+		b    *Balancer
+		next http.RoundTripper
+		key  func(*http.Request) string
+	}
+
+	// inflightBody releases the in-flight request of the backend once the body is closed.
+	inflightBody struct {
+		io.ReadCloser
+		be   *Backend
+		once sync.Once
+	}
+)
+
+// errServer is reported to the CircuitBreaker for 5xx responses.
+var errServer = errors.New("server error")
+
+// RoundTripper returns an http.RoundTripper which sends the request to the backend picked for it,
+// the host of the request URL is replaced by the backend name,
+// and a 5xx response is counted as failure by the CircuitBreaker of the backend.
+// The request is in flight on the backend until the response body is closed.
+// If next is nil, http.DefaultTransport is used. If key is nil, empty key is used.
+func (b *Balancer) RoundTripper(next http.RoundTripper, key func(*http.Request) string) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &roundTripper{
+		b:    b,
+		next: next,
+		key:  key,
+	}
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var k string
+	if rt.key != nil {
+		k = rt.key(req)
+	}
+
+	be, err := rt.b.Pick(k)
+	if err != nil {
+		return nil, err
+	}
+
+	atomic.AddInt64(&be.inflight, 1)
+	var resp *http.Response
+	_, err = be.cb.Execute(func() (interface{}, error) {
+		r := new(http.Request)
+		*r = *req
+		u := *req.URL
+		u.Host = be.Name
+		r.URL = &u
+
+		var err error
+		resp, err = rt.next.RoundTrip(r)
+		if err == nil && resp.StatusCode >= http.StatusInternalServerError {
+			return nil, errServer
+		}
+		return nil, err
+	})
+	if err == errServer {
+		err = nil
+	}
+	if err != nil || resp == nil {
+		atomic.AddInt64(&be.inflight, -1)
+		return nil, err
+	}
+	resp.Body = &inflightBody{ReadCloser: resp.Body, be: be}
+	return resp, nil
+}
+
+func (ib *inflightBody) Close() error {
+	err := ib.ReadCloser.Close()
+	ib.once.Do(func() {
+		atomic.AddInt64(&ib.be.inflight, -1)
+	})
+	return err
+}
@@ -2,6 +2,8 @@
 package bloom
 
 import (
+	"encoding"
+	"io"
 	"math"
 )
 
@@ -18,6 +20,13 @@ type (
 		K() uint64
 		N() uint64
 		Clear()
+
+		// the filter is encoded with a versioned header,
+		// it can be decoded by Unmarshal or Read.
+		encoding.BinaryMarshaler
+		encoding.BinaryUnmarshaler
+		io.WriterTo
+		io.ReaderFrom
 	}
 
 	// CountingBloom is the bloom filter which allows deletion of entries.
@@ -0,0 +1,138 @@
+package bloom
+
+import (
+	"math"
+	"time"
+)
+
+type (
+	// agingBloomFilter is the sliding window bloom filter which forgets the elements older than window,
+	// the window is split into agingGenerations generations of span,
+	// each generation is a standard bloom filter holding the elements added within its span,
+	// the generation is reused once it's out of the window.
+	agingBloomFilter struct {
+		filterz []*bloomFilterBit // generations indexed by epoch % len(filterz)
+		epochs  []uint64          // epoch of the generations
+		span    uint64            // span of a generation in nanoseconds
+		now     func() time.Time  // time.Now if nil, overridden in tests
+	}
+)
+
+const agingGenerations = 4
+
+// NewAging creates sliding window bloom filter based on the provided window/n/p.
+// window is the duration an element is kept for,
+// the element is forgotten between window and window * (1 + 1/4) after it's added.
+// n is the estimated number of elements added within window.
+// p is the false positive probability.
+func NewAging(window time.Duration, n uint64, p float64) Bloom {
+	span := uint64(window) / agingGenerations
+	if span < 1 {
+		span = 1
+	}
+	// one more generation than the window to keep the elements for the whole window,
+	// each generation is sized for n as the elements may be added in a burst.
+	m, k := Guess(n, p/(agingGenerations+1))
+	bf := &agingBloomFilter{
+		filterz: make([]*bloomFilterBit, agingGenerations+1),
+		epochs:  make([]uint64, agingGenerations+1),
+		span:    span,
+	}
+	for i := range bf.filterz {
+		bf.filterz[i] = NewB(m, k).(*bloomFilterBit)
+	}
+	return bf
+}
+
+func (bf *agingBloomFilter) Add(entry []byte) {
+	epoch := bf.epoch()
+	i := epoch % uint64(len(bf.filterz))
+	if bf.epochs[i] != epoch {
+		bf.filterz[i].Clear()
+		bf.epochs[i] = epoch
+	}
+	bf.filterz[i].Add(entry)
+}
+
+func (bf *agingBloomFilter) AddString(entry string) {
+	bf.Add([]byte(entry))
+}
+
+func (bf *agingBloomFilter) Exist(entry []byte) bool {
+	epoch := bf.epoch()
+	for i, f := range bf.filterz {
+		if bf.live(i, epoch) && f.Exist(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+func (bf *agingBloomFilter) ExistString(entry string) bool {
+	return bf.Exist([]byte(entry))
+}
+
+func (bf *agingBloomFilter) FalsePositive() float64 {
+	epoch := bf.epoch()
+	rez := 1.0
+	for i, f := range bf.filterz {
+		if bf.live(i, epoch) {
+			rez *= (1.0 - f.FalsePositive())
+		}
+	}
+	return 1.0 - rez
+}
+
+// GuessFalsePositive returns the false positive rate with n elements added evenly within window.
+func (bf *agingBloomFilter) GuessFalsePositive(n uint64) float64 {
+	fp := bf.filterz[0].GuessFalsePositive((n + agingGenerations - 1) / agingGenerations)
+	return 1.0 - math.Pow(1.0-fp, agingGenerations+1)
+}
+
+func (bf *agingBloomFilter) M() uint64 {
+	m := uint64(0)
+	for _, f := range bf.filterz {
+		m += f.M()
+	}
+	return m
+}
+
+func (bf *agingBloomFilter) K() uint64 {
+	return bf.filterz[0].K()
+}
+
+// N returns the number of elements added within window.
+func (bf *agingBloomFilter) N() uint64 {
+	epoch := bf.epoch()
+	n := uint64(0)
+	for i, f := range bf.filterz {
+		if bf.live(i, epoch) {
+			n += f.N()
+		}
+	}
+	return n
+}
+
+func (bf *agingBloomFilter) Clear() {
+	for i, f := range bf.filterz {
+		f.Clear()
+		bf.epochs[i] = 0
+	}
+}
+
+func (bf *agingBloomFilter) epoch() uint64 {
+	return uint64(bf.timeNow().UnixNano()) / bf.span
+}
+
+func (bf *agingBloomFilter) timeNow() time.Time {
+	if bf.now != nil {
+		return bf.now()
+	}
+	return time.Now()
+}
+
+// live returns true if the generation i is within the window at epoch.
+func (bf *agingBloomFilter) live(i int, epoch uint64) bool {
+	e := bf.epochs[i]
+	return e <= epoch && epoch-e < uint64(len(bf.filterz))
+}
@@ -0,0 +1,61 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (f *fakeClock) now() time.Time { return f.t }
+
+func (f *fakeClock) advance(d time.Duration) { f.t = f.t.Add(d) }
+
+func TestAgingWindow(t *testing.T) {
+	window := 200 * time.Millisecond
+	clock := &fakeClock{time.Unix(0, 0)}
+	f := NewAging(window, 1000, 0.01).(*agingBloomFilter)
+	f.now = clock.now
+	exist := func(from, to uint32) (all, some bool) {
+		all = true
+		for i := from; i < to; i++ {
+			n := make([]byte, 4)
+			binary.BigEndian.PutUint32(n, i)
+			e := f.Exist(n)
+			all, some = all && e, some || e
+		}
+		return
+	}
+	for i := uint32(0); i < 200; i++ {
+		if i == 100 {
+			clock.advance(window / 2)
+		}
+		n := make([]byte, 4)
+		binary.BigEndian.PutUint32(n, i)
+		f.Add(n)
+	}
+	if all, _ := exist(0, 200); !all {
+		t.Error("the elements within window should Exist.")
+	}
+
+	// the first elements are kept for window.
+	clock.advance(window/2 - 1)
+	if all, _ := exist(0, 100); !all {
+		t.Error("the elements within window should Exist.")
+	}
+
+	// the first elements are forgotten within window * 1.25.
+	clock.advance(window/4 + 1)
+	if _, some := exist(0, 100); some {
+		t.Error("the elements older than window should be forgotten.")
+	}
+	if all, _ := exist(100, 200); !all {
+		t.Error("the elements within window should Exist.")
+	}
+	if f.N() != 100 {
+		t.Errorf("N() got %d want 100", f.N())
+	}
+}
@@ -0,0 +1,34 @@
+package bloom_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andy2046/gopie/pkg/bloom"
+)
+
+func TestAgingBasic(t *testing.T) {
+	f := bloom.NewAging(time.Minute, 1000, 0.01)
+	e1 := []byte("Boss")
+	e2 := []byte("Joke")
+	f.Add(e1)
+	if !f.Exist(e1) {
+		t.Errorf("%q should Exist.", e1)
+	}
+	if f.Exist(e2) {
+		t.Errorf("%q should not Exist.", e2)
+	}
+	if f.N() != 1 {
+		t.Errorf("N() %v is not correct", f.N())
+	}
+
+	addRange(f, 0, 1000)
+	if fp := f.FalsePositive(); fp > 0.01 {
+		t.Errorf("false positive rate got %f want at most 0.01", fp)
+	}
+
+	f.Clear()
+	if f.N() != 0 || f.Exist(e1) {
+		t.Error("filter should be empty after Clear.")
+	}
+}
@@ -2,17 +2,15 @@ package bloom
 
 import (
 	"math"
-
-	"github.com/andy2046/bitmap"
 )
 
 type (
 	bloomFilterBit struct {
-		bitmap *bitmap.Bitmap // bloom filter bitmap
-		k      uint64         // number of hash functions
-		n      uint64         // number of elements in the bloom filter
-		m      uint64         // size of the bloom filter bits
-		shift  uint8          // the shift to get high/low bit fragments
+		bitmap []uint64 // bloom filter bitmap
+		k      uint64   // number of hash functions
+		n      uint64   // number of elements in the bloom filter
+		m      uint64   // size of the bloom filter bits
+		shift  uint8    // the shift to get high/low bit fragments
 	}
 )
 
@@ -22,7 +20,7 @@ type (
 func NewB(m, k uint64) Bloom {
 	mm, exponent := adjustM(m)
 	return &bloomFilterBit{
-		bitmap: bitmap.New(mm),
+		bitmap: make([]uint64, mm/64),
 		m:      mm - 1, // x % 2^i = x & (2^i - 1)
 		k:      k,
 		shift:  64 - exponent,
@@ -42,7 +40,7 @@ func (bf *bloomFilterBit) Add(entry []byte) {
 	h := hash >> bf.shift
 	l := hash << bf.shift >> bf.shift
 	for i := uint64(0); i < bf.k; i++ {
-		bf.bitmap.SetBit((h+i*l)&bf.m, true)
+		bf.setBit((h + i*l) & bf.m)
 	}
 	bf.n++
 }
@@ -57,7 +55,7 @@ func (bf *bloomFilterBit) Exist(entry []byte) bool {
 	l := hash << bf.shift >> bf.shift
 
 	for i := uint64(0); i < bf.k; i++ {
-		if !bf.bitmap.GetBit((h + i*l) & bf.m) {
+		if !bf.getBit((h + i*l) & bf.m) {
 			return false
 		}
 	}
@@ -92,13 +90,20 @@ func (bf *bloomFilterBit) N() uint64 {
 }
 
 func (bf *bloomFilterBit) Clear() {
-	s := bf.bitmap.Size()
-	for i := uint64(0); i < s; i++ {
-		bf.bitmap.SetBit(i, false)
+	for i := range bf.bitmap {
+		bf.bitmap[i] = 0
 	}
 	bf.n = 0
 }
 
+func (bf *bloomFilterBit) setBit(i uint64) {
+	bf.bitmap[i>>6] |= uint64(1) << (i & 63)
+}
+
+func (bf *bloomFilterBit) getBit(i uint64) bool {
+	return bf.bitmap[i>>6]&(uint64(1)<<(i&63)) != 0
+}
+
 func (bf *bloomFilterBit) estimatedFillRatio() float64 {
 	return 1 - math.Exp(-float64(bf.n)/math.Ceil(float64(bf.m)/float64(bf.k)))
 }
@@ -0,0 +1,65 @@
+package bloom_test
+
+import (
+	"testing"
+
+	"github.com/andy2046/gopie/pkg/bloom"
+)
+
+func TestBlockedBasic(t *testing.T) {
+	f := bloom.NewBlocked(1000, 4)
+	e1 := []byte("Boss")
+	e2 := []byte("Joke")
+	e3 := []byte("Emotion")
+	f.Add(e1)
+	e3b := f.Exist(e3)
+	e1a := f.Exist(e1)
+	e2a := f.Exist(e2)
+	f.Add(e3)
+	e3a := f.Exist(e3)
+	if !e1a {
+		t.Errorf("%q should Exist.", e1)
+	}
+	if e2a {
+		t.Errorf("%q should not Exist.", e2)
+	}
+	if e3b {
+		t.Errorf("%q should not Exist the first time we check.", e3)
+	}
+	if !e3a {
+		t.Errorf("%q should Exist the second time we check.", e3)
+	}
+
+	f.Clear()
+	if f.N() != 0 || f.Exist(e1) {
+		t.Error("filter should be empty after Clear.")
+	}
+}
+
+func TestBlockedRange(t *testing.T) {
+	f := bloom.NewBlockedGuess(10000, 0.001)
+	addRange(f, 0, 10000)
+	if !existRange(f, 0, 10000) {
+		t.Error("all the elements should Exist.")
+	}
+	if f.N() != 10000 {
+		t.Errorf("N() %v is not correct", f.N())
+	}
+}
+
+func TestBlockedM(t *testing.T) {
+	f := bloom.NewBlocked(1000, 4)
+	if f.M() != 1024 {
+		t.Errorf("M() %v is not correct", f.M())
+	}
+}
+
+func TestBlockedK(t *testing.T) {
+	f := bloom.NewBlocked(1000, 4)
+	if f.K() != 4 {
+		t.Errorf("K() %v is not correct", f.K())
+	}
+	if f := bloom.NewBlocked(1000, 1000); f.K() != 512 {
+		t.Errorf("K() %v is not correct", f.K())
+	}
+}
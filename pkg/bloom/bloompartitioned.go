@@ -0,0 +1,118 @@
+package bloom
+
+import (
+	"math"
+)
+
+type (
+	// partitionedBloomFilter is the partitioned bloom filter,
+	// the bitmap is split into k slices and each hash function sets one bit in its own slice,
+	// so that the bits of an element never collide with each other.
+	partitionedBloomFilter struct {
+		bitmap []uint64 // bloom filter bitmap, k slices of s bits
+		k      uint64   // number of hash functions
+		n      uint64   // number of elements in the bloom filter
+		s      uint64   // size of the slice bits, multiple of 64
+	}
+)
+
+const maxSliceSize = 1 << 32 // the slice is indexed by 32-bit hash fragments
+
+// NewPartitioned creates partitioned bloom filter based on the provided m/k.
+// m is the size of bloom filter bits, rounded up like NewB then split into k slices of a multiple of 64 bits.
+// k is the number of hash functions.
+func NewPartitioned(m, k uint64) Bloom {
+	if k < 1 {
+		k = 1
+	}
+	mm, _ := adjustM(m)
+	s := (mm + 64*k - 1) / (64 * k) * 64
+	if s > maxSliceSize {
+		s = maxSliceSize
+	}
+	return &partitionedBloomFilter{
+		bitmap: make([]uint64, k*s/64),
+		k:      k,
+		s:      s,
+	}
+}
+
+// NewPartitionedGuess estimates m/k based on the provided n/p then creates partitioned bloom filter.
+// n is the estimated number of elements in the bloom filter.
+// p is the false positive probability.
+func NewPartitionedGuess(n uint64, p float64) Bloom {
+	m, k := Guess(n, p)
+	return NewPartitioned(m, k)
+}
+
+func (bf *partitionedBloomFilter) Add(entry []byte) {
+	h, l := bf.hash(entry)
+	for i := uint64(0); i < bf.k; i++ {
+		idx := bf.index(i, h, l)
+		bf.bitmap[idx>>6] |= 1 << (idx & 63)
+	}
+	bf.n++
+}
+
+func (bf *partitionedBloomFilter) AddString(entry string) {
+	bf.Add([]byte(entry))
+}
+
+func (bf *partitionedBloomFilter) Exist(entry []byte) bool {
+	h, l := bf.hash(entry)
+	for i := uint64(0); i < bf.k; i++ {
+		idx := bf.index(i, h, l)
+		if bf.bitmap[idx>>6]&(1<<(idx&63)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (bf *partitionedBloomFilter) ExistString(entry string) bool {
+	return bf.Exist([]byte(entry))
+}
+
+func (bf *partitionedBloomFilter) FalsePositive() float64 {
+	return bf.GuessFalsePositive(bf.n)
+}
+
+// GuessFalsePositive returns the probability that the bit is set in every slice,
+// each slice holds one bit per element.
+func (bf *partitionedBloomFilter) GuessFalsePositive(n uint64) float64 {
+	return math.Pow(1-math.Exp(-float64(n)/float64(bf.s)),
+		float64(bf.k))
+}
+
+func (bf *partitionedBloomFilter) M() uint64 {
+	return bf.k * bf.s
+}
+
+func (bf *partitionedBloomFilter) K() uint64 {
+	return bf.k
+}
+
+func (bf *partitionedBloomFilter) N() uint64 {
+	return bf.n
+}
+
+func (bf *partitionedBloomFilter) Clear() {
+	for i := range bf.bitmap {
+		bf.bitmap[i] = 0
+	}
+	bf.n = 0
+}
+
+// hash returns the 32-bit fragments for double hashing.
+func (bf *partitionedBloomFilter) hash(entry []byte) (h, l uint32) {
+	hash := sipHash(entry)
+	return uint32(hash >> 32), uint32(hash)
+}
+
+// index returns the bit of the i-th hash function in the i-th slice,
+// the 32-bit hash is mapped to [0, s) by multiply-shift instead of modulo.
+func (bf *partitionedBloomFilter) index(i uint64, h, l uint32) uint64 {
+	x := h + uint32(i)*l
+	return i*bf.s + uint64(x)*bf.s>>32
+}
@@ -2,8 +2,6 @@ package bloom
 
 import (
 	"math"
-
-	"github.com/andy2046/bitmap"
 )
 
 type (
@@ -45,7 +43,7 @@ func NewSGuess(n uint64, p, r float64) Bloom {
 	}
 
 	sBF.filterz = append(sBF.filterz, &bloomFilterBit{
-		bitmap: bitmap.New(mm),
+		bitmap: make([]uint64, mm/64),
 		m:      mm - 1, // x % 2^i = x & (2^i - 1)
 		k:      k,
 		shift:  64 - exponent,
@@ -60,7 +58,7 @@ func (bf *scalableBloomFilter) Add(entry []byte) {
 		m, k := Guess(bf.n, fp)
 		mm, exponent := adjustM(m)
 		bf.filterz = append(bf.filterz, &bloomFilterBit{
-			bitmap: bitmap.New(mm),
+			bitmap: make([]uint64, mm/64),
 			m:      mm - 1, // x % 2^i = x & (2^i - 1)
 			k:      k,
 			shift:  64 - exponent,
@@ -128,7 +126,7 @@ func (bf *scalableBloomFilter) Clear() {
 	m, k := Guess(bf.n, bf.p)
 	mm, exponent := adjustM(m)
 	bf.filterz = append(bf.filterz, &bloomFilterBit{
-		bitmap: bitmap.New(mm),
+		bitmap: make([]uint64, mm/64),
 		m:      mm - 1, // x % 2^i = x & (2^i - 1)
 		k:      k,
 		shift:  64 - exponent,
@@ -0,0 +1,153 @@
+package bloom
+
+/*
+   http://webdocs.cs.ualberta.ca/~drafiei/papers/DupDet06Sigmod.pdf
+*/
+
+import (
+	"math"
+)
+
+type (
+	// stableBloomFilter is the Stable bloom filter for duplicate detection in unbounded streams,
+	// every Add decrements p random cells before setting the k cells of the element to max,
+	// so that the fraction of zero cells and the false positive rate converge to a constant.
+	stableBloomFilter struct {
+		cells []uint8 // bloom filter cells
+		k     uint64  // number of hash functions
+		n     uint64  // number of elements added to the bloom filter
+		m     uint64  // size of the bloom filter cells
+		p     uint64  // number of cells to decrement per Add
+		max   uint8   // the value a cell is set to
+		shift uint8   // the shift to get high/low bit fragments
+		rnd   uint64  // xorshift state to choose the cells to decrement
+	}
+)
+
+const stableMaxDefault uint8 = 3
+
+// NewStable creates Stable bloom filter based on the provided m/k/p/max.
+// m is the number of cells.
+// k is the number of hash functions.
+// p is the number of cells to decrement per Add.
+// max is the value a cell is set to, an element is forgotten after about max * m / p Adds.
+// The false positive rate converges to FalsePositive() on an infinite stream,
+// at the cost of false negatives for the elements which are not added recently.
+func NewStable(m, k, p uint64, max uint8) Bloom {
+	mm, exponent := adjustM(m)
+	if k < 1 {
+		k = 1
+	}
+	if p < 1 {
+		p = 1
+	} else if p > mm {
+		p = mm
+	}
+	if max < 1 {
+		max = 1
+	}
+	return &stableBloomFilter{
+		cells: make([]uint8, mm),
+		m:     mm - 1, // x % 2^i = x & (2^i - 1)
+		k:     k,
+		p:     p,
+		max:   max,
+		shift: 64 - exponent,
+		rnd:   0x9e3779b97f4a7c15,
+	}
+}
+
+// NewStableGuess estimates k/p based on the provided m/fpRate then creates Stable bloom filter.
+// m is the number of cells.
+// fpRate is the false positive probability the filter converges to.
+func NewStableGuess(m uint64, fpRate float64) Bloom {
+	mm, _ := adjustM(m)
+	k := math.Ceil(math.Log2(1/fpRate) / 2)
+	k = math.Max(1, math.Min(k, 5))
+	// the fraction of zero cells at the stable point for fpRate.
+	zero := 1 - math.Pow(fpRate, 1/k)
+	p := 1 / ((math.Pow(zero, -1/float64(stableMaxDefault)) - 1) * (1/k - 1/float64(mm)))
+	return NewStable(mm, uint64(k), uint64(math.Max(1, math.Ceil(p))), stableMaxDefault)
+}
+
+func (bf *stableBloomFilter) Add(entry []byte) {
+	start := bf.random()
+	for i := uint64(0); i < bf.p; i++ {
+		if c := &bf.cells[(start+i)&bf.m]; *c > 0 {
+			*c--
+		}
+	}
+
+	hash := sipHash(entry)
+	h := hash >> bf.shift
+	l := hash << bf.shift >> bf.shift
+	for i := uint64(0); i < bf.k; i++ {
+		bf.cells[(h+i*l)&bf.m] = bf.max
+	}
+	bf.n++
+}
+
+func (bf *stableBloomFilter) AddString(entry string) {
+	bf.Add([]byte(entry))
+}
+
+func (bf *stableBloomFilter) Exist(entry []byte) bool {
+	hash := sipHash(entry)
+	h := hash >> bf.shift
+	l := hash << bf.shift >> bf.shift
+	for i := uint64(0); i < bf.k; i++ {
+		if bf.cells[(h+i*l)&bf.m] == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (bf *stableBloomFilter) ExistString(entry string) bool {
+	return bf.Exist([]byte(entry))
+}
+
+// FalsePositive returns the false positive rate at the stable point,
+// which is the limit of GuessFalsePositive.
+func (bf *stableBloomFilter) FalsePositive() float64 {
+	k, m := float64(bf.k), float64(bf.m+1)
+	zero := math.Pow(1/(1+1/(float64(bf.p)*(1/k-1/m))), float64(bf.max))
+	return math.Pow(1-zero, k)
+}
+
+// GuessFalsePositive returns the upper bound of the false positive rate after n Adds,
+// which is the lesser of the standard bloom filter and the stable point.
+func (bf *stableBloomFilter) GuessFalsePositive(n uint64) float64 {
+	fp := math.Pow((1 - math.Exp(-float64(bf.k*n)/float64(bf.m))),
+		float64(bf.k))
+	return math.Min(fp, bf.FalsePositive())
+}
+
+func (bf *stableBloomFilter) M() uint64 {
+	return bf.m + 1
+}
+
+func (bf *stableBloomFilter) K() uint64 {
+	return bf.k
+}
+
+// N returns the number of elements added,
+// including the ones which have been forgotten.
+func (bf *stableBloomFilter) N() uint64 {
+	return bf.n
+}
+
+func (bf *stableBloomFilter) Clear() {
+	for i := range bf.cells {
+		bf.cells[i] = 0
+	}
+	bf.n = 0
+}
+
+func (bf *stableBloomFilter) random() uint64 {
+	bf.rnd ^= bf.rnd << 13
+	bf.rnd ^= bf.rnd >> 7
+	bf.rnd ^= bf.rnd << 17
+	return bf.rnd
+}
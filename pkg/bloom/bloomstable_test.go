@@ -0,0 +1,60 @@
+package bloom_test
+
+import (
+	"testing"
+
+	"github.com/andy2046/gopie/pkg/bloom"
+)
+
+func TestStableBasic(t *testing.T) {
+	f := bloom.NewStable(1000, 4, 10, 3)
+	e1 := []byte("Boss")
+	e2 := []byte("Joke")
+	f.Add(e1)
+	if !f.Exist(e1) {
+		t.Errorf("%q should Exist.", e1)
+	}
+	if f.Exist(e2) {
+		t.Errorf("%q should not Exist.", e2)
+	}
+	if f.M() != 1024 || f.K() != 4 || f.N() != 1 {
+		t.Errorf("m/k/n %d/%d/%d is not correct", f.M(), f.K(), f.N())
+	}
+
+	f.Clear()
+	if f.N() != 0 || f.Exist(e1) {
+		t.Error("filter should be empty after Clear.")
+	}
+}
+
+func TestStableStream(t *testing.T) {
+	f := bloom.NewStableGuess(1<<16, 0.01)
+	want := f.FalsePositive()
+	if want > 0.011 {
+		t.Errorf("stable false positive rate got %f want at most 0.01", want)
+	}
+
+	// the false positive rate is bounded on a stream much larger than m.
+	addRange(f, 0, 1000000)
+	if !existRange(f, 999900, 1000000) {
+		t.Error("the recent elements should Exist.")
+	}
+	if existRange(f, 0, 1000) {
+		t.Error("the old elements should be forgotten.")
+	}
+
+	fp := 0
+	for i := uint32(2000000); i < 2100000; i++ {
+		if f.Exist(key(i)) {
+			fp++
+		}
+	}
+	rate := float64(fp) / 100000
+	t.Logf("m=%d k=%d measured=%.5f estimated=%.5f", f.M(), f.K(), rate, want)
+	if rate > 1.2*want {
+		t.Errorf("false positive rate got %f want about %f", rate, want)
+	}
+	if f.GuessFalsePositive(10) > want || f.GuessFalsePositive(1<<30) != want {
+		t.Error("GuessFalsePositive should be bounded by the stable point.")
+	}
+}
@@ -0,0 +1,373 @@
+package bloom
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// syncBloomFilterBit is the goroutine-safe standard bloom filter,
+	// the bits are set by atomic CAS.
+	syncBloomFilterBit struct {
+		n      uint64   // number of elements in the bloom filter, accessed atomically
+		bitmap []uint64 // bloom filter bitmap
+		k      uint64   // number of hash functions
+		m      uint64   // size of the bloom filter bits
+		shift  uint8    // the shift to get high/low bit fragments
+	}
+
+	// syncBloomFilter is the goroutine-safe counting bloom filter,
+	// the counters are updated by atomic CAS.
+	syncBloomFilter struct {
+		n      uint64   // number of elements in the bloom filter, accessed atomically
+		bitmap []uint32 // bloom filter counter, saturated at maxCounter
+		k      uint64   // number of hash functions
+		m      uint64   // size of the bloom filter bits
+		shift  uint8    // the shift to get high/low bit fragments
+	}
+
+	// syncScalableBloomFilter is the goroutine-safe scalable bloom filter,
+	// the growth of the filters list is guarded by mu.
+	syncScalableBloomFilter struct {
+		count     uint64 // number of elements in the bloom filter, accessed atomically
+		mu        sync.RWMutex
+		filterz   []*syncBloomFilterBit // bloom filters list
+		n         uint64                // estimated number of elements
+		p         float64               // target False Positive rate
+		r         float64               // optimal tightening ratio
+		fillRatio float64               // fill ratio
+	}
+)
+
+// NewSyncB is like NewB but the bloom filter is safe for concurrent use.
+func NewSyncB(m, k uint64) Bloom {
+	return newSyncB(m, k)
+}
+
+// NewSyncBGuess is like NewBGuess but the bloom filter is safe for concurrent use.
+func NewSyncBGuess(n uint64, p float64) Bloom {
+	m, k := Guess(n, p)
+	return NewSyncB(m, k)
+}
+
+// NewSync is like New but the counting bloom filter is safe for concurrent use.
+func NewSync(m, k uint64) CountingBloom {
+	mm, exponent := adjustM(m)
+	return &syncBloomFilter{
+		bitmap: make([]uint32, mm),
+		m:      mm - 1, // x % 2^i = x & (2^i - 1)
+		k:      k,
+		shift:  64 - exponent,
+	}
+}
+
+// NewSyncGuess is like NewGuess but the counting bloom filter is safe for concurrent use.
+func NewSyncGuess(n uint64, p float64) CountingBloom {
+	m, k := Guess(n, p)
+	return NewSync(m, k)
+}
+
+// NewSyncS is like NewS but the scalable bloom filter is safe for concurrent use.
+func NewSyncS(fpRate float64) Bloom {
+	return NewSyncSGuess(10000, fpRate, rDefault)
+}
+
+// NewSyncSGuess is like NewSGuess but the scalable bloom filter is safe for concurrent use.
+func NewSyncSGuess(n uint64, p, r float64) Bloom {
+	m, k := Guess(n, p)
+	return &syncScalableBloomFilter{
+		filterz:   []*syncBloomFilterBit{newSyncB(m, k)},
+		r:         r,
+		fillRatio: fillRatio,
+		p:         p,
+		n:         n,
+	}
+}
+
+func newSyncB(m, k uint64) *syncBloomFilterBit {
+	mm, exponent := adjustM(m)
+	return &syncBloomFilterBit{
+		bitmap: make([]uint64, mm/64),
+		m:      mm - 1, // x % 2^i = x & (2^i - 1)
+		k:      k,
+		shift:  64 - exponent,
+	}
+}
+
+func (bf *syncBloomFilterBit) Add(entry []byte) {
+	hash := sipHash(entry)
+	h := hash >> bf.shift
+	l := hash << bf.shift >> bf.shift
+	for i := uint64(0); i < bf.k; i++ {
+		bf.setBit((h + i*l) & bf.m)
+	}
+	atomic.AddUint64(&bf.n, 1)
+}
+
+func (bf *syncBloomFilterBit) AddString(entry string) {
+	bf.Add([]byte(entry))
+}
+
+func (bf *syncBloomFilterBit) Exist(entry []byte) bool {
+	hash := sipHash(entry)
+	h := hash >> bf.shift
+	l := hash << bf.shift >> bf.shift
+
+	for i := uint64(0); i < bf.k; i++ {
+		if !bf.getBit((h + i*l) & bf.m) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (bf *syncBloomFilterBit) ExistString(entry string) bool {
+	return bf.Exist([]byte(entry))
+}
+
+func (bf *syncBloomFilterBit) FalsePositive() float64 {
+	return bf.GuessFalsePositive(bf.N())
+}
+
+func (bf *syncBloomFilterBit) GuessFalsePositive(n uint64) float64 {
+	return math.Pow((1 - math.Exp(-float64(bf.k*n)/float64(bf.m))),
+		float64(bf.k))
+}
+
+func (bf *syncBloomFilterBit) M() uint64 {
+	return bf.m + 1
+}
+
+func (bf *syncBloomFilterBit) K() uint64 {
+	return bf.k
+}
+
+func (bf *syncBloomFilterBit) N() uint64 {
+	return atomic.LoadUint64(&bf.n)
+}
+
+// Clear is not atomic, the elements added concurrently may be partially cleared.
+func (bf *syncBloomFilterBit) Clear() {
+	for i := range bf.bitmap {
+		atomic.StoreUint64(&bf.bitmap[i], 0)
+	}
+	atomic.StoreUint64(&bf.n, 0)
+}
+
+func (bf *syncBloomFilterBit) setBit(i uint64) {
+	word, mask := &bf.bitmap[i>>6], uint64(1)<<(i&63)
+	for {
+		old := atomic.LoadUint64(word)
+		if old&mask != 0 || atomic.CompareAndSwapUint64(word, old, old|mask) {
+			return
+		}
+	}
+}
+
+func (bf *syncBloomFilterBit) getBit(i uint64) bool {
+	return atomic.LoadUint64(&bf.bitmap[i>>6])&(uint64(1)<<(i&63)) != 0
+}
+
+func (bf *syncBloomFilterBit) estimatedFillRatio() float64 {
+	return 1 - math.Exp(-float64(bf.N())/math.Ceil(float64(bf.m)/float64(bf.k)))
+}
+
+func (bf *syncBloomFilter) Add(entry []byte) {
+	hash := sipHash(entry)
+	h := hash >> bf.shift
+	l := hash << bf.shift >> bf.shift
+	for i := uint64(0); i < bf.k; i++ {
+		c := &bf.bitmap[(h+i*l)&bf.m]
+		for {
+			old := atomic.LoadUint32(c)
+			// avoid overflow
+			if old >= uint32(maxCounter) || atomic.CompareAndSwapUint32(c, old, old+1) {
+				break
+			}
+		}
+	}
+	atomic.AddUint64(&bf.n, 1)
+}
+
+func (bf *syncBloomFilter) AddString(entry string) {
+	bf.Add([]byte(entry))
+}
+
+func (bf *syncBloomFilter) Remove(entry []byte) {
+	hash := sipHash(entry)
+	h := hash >> bf.shift
+	l := hash << bf.shift >> bf.shift
+	for i := uint64(0); i < bf.k; i++ {
+		if atomic.LoadUint32(&bf.bitmap[(h+i*l)&bf.m]) == 0 {
+			return
+		}
+	}
+
+	for i := uint64(0); i < bf.k; i++ {
+		c := &bf.bitmap[(h+i*l)&bf.m]
+		for {
+			old := atomic.LoadUint32(c)
+			// avoid overflow
+			if old == 0 || atomic.CompareAndSwapUint32(c, old, old-1) {
+				break
+			}
+		}
+	}
+	atomic.AddUint64(&bf.n, ^uint64(0))
+}
+
+func (bf *syncBloomFilter) RemoveString(entry string) {
+	bf.Remove([]byte(entry))
+}
+
+func (bf *syncBloomFilter) Exist(entry []byte) bool {
+	hash := sipHash(entry)
+	h := hash >> bf.shift
+	l := hash << bf.shift >> bf.shift
+	for i := uint64(0); i < bf.k; i++ {
+		if atomic.LoadUint32(&bf.bitmap[(h+i*l)&bf.m]) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (bf *syncBloomFilter) ExistString(entry string) bool {
+	return bf.Exist([]byte(entry))
+}
+
+func (bf *syncBloomFilter) FalsePositive() float64 {
+	return bf.GuessFalsePositive(bf.N())
+}
+
+func (bf *syncBloomFilter) GuessFalsePositive(n uint64) float64 {
+	return math.Pow((1 - math.Exp(-float64(bf.k*n)/float64(bf.m))),
+		float64(bf.k))
+}
+
+func (bf *syncBloomFilter) M() uint64 {
+	return bf.m + 1
+}
+
+func (bf *syncBloomFilter) K() uint64 {
+	return bf.k
+}
+
+func (bf *syncBloomFilter) N() uint64 {
+	return atomic.LoadUint64(&bf.n)
+}
+
+// Clear is not atomic, the elements added concurrently may be partially cleared.
+func (bf *syncBloomFilter) Clear() {
+	for i := range bf.bitmap {
+		atomic.StoreUint32(&bf.bitmap[i], 0)
+	}
+	atomic.StoreUint64(&bf.n, 0)
+}
+
+func (bf *syncScalableBloomFilter) Add(entry []byte) {
+	bf.mu.RLock()
+	last := bf.filterz[len(bf.filterz)-1]
+	full := last.estimatedFillRatio() >= bf.fillRatio
+	bf.mu.RUnlock()
+
+	if full {
+		last = bf.grow(last)
+	}
+	last.Add(entry)
+	atomic.AddUint64(&bf.count, 1)
+}
+
+// grow appends a new filter if last is still the last filter,
+// returns the last filter.
+func (bf *syncScalableBloomFilter) grow(last *syncBloomFilterBit) *syncBloomFilterBit {
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+
+	if l := bf.filterz[len(bf.filterz)-1]; l != last {
+		// another goroutine already grows the filters list
+		return l
+	}
+	fp := bf.p * math.Pow(bf.r, float64(len(bf.filterz)))
+	m, k := Guess(bf.n, fp)
+	last = newSyncB(m, k)
+	bf.filterz = append(bf.filterz, last)
+	return last
+}
+
+func (bf *syncScalableBloomFilter) AddString(entry string) {
+	bf.Add([]byte(entry))
+}
+
+func (bf *syncScalableBloomFilter) Exist(entry []byte) bool {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	for _, f := range bf.filterz {
+		if f.Exist(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+func (bf *syncScalableBloomFilter) ExistString(entry string) bool {
+	return bf.Exist([]byte(entry))
+}
+
+func (bf *syncScalableBloomFilter) FalsePositive() float64 {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	rez := 1.0
+	for _, f := range bf.filterz {
+		rez *= (1.0 - f.FalsePositive())
+	}
+	return 1.0 - rez
+}
+
+func (bf *syncScalableBloomFilter) GuessFalsePositive(n uint64) float64 {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	rez := 1.0
+	for _, f := range bf.filterz {
+		rez *= (1.0 - f.GuessFalsePositive(n))
+	}
+	return 1.0 - rez
+}
+
+func (bf *syncScalableBloomFilter) M() uint64 {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	m := uint64(0)
+	for _, f := range bf.filterz {
+		m += f.M()
+	}
+	return m
+}
+
+func (bf *syncScalableBloomFilter) K() uint64 {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	return bf.filterz[0].K()
+}
+
+func (bf *syncScalableBloomFilter) N() uint64 {
+	return atomic.LoadUint64(&bf.count)
+}
+
+// Clear is not atomic, the elements added concurrently may be partially cleared.
+func (bf *syncScalableBloomFilter) Clear() {
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+
+	m, k := Guess(bf.n, bf.p)
+	bf.filterz = []*syncBloomFilterBit{newSyncB(m, k)}
+	atomic.StoreUint64(&bf.count, 0)
+}
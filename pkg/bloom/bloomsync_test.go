@@ -0,0 +1,132 @@
+package bloom_test
+
+import (
+	"encoding/binary"
+	"sync"
+	"testing"
+
+	"github.com/andy2046/gopie/pkg/bloom"
+)
+
+func TestSyncConcurrent(t *testing.T) {
+	for name, f := range map[string]bloom.Bloom{
+		"counting": bloom.NewSyncGuess(10000, 0.01),
+		"bit":      bloom.NewSyncBGuess(10000, 0.01),
+		"scalable": bloom.NewSyncSGuess(100, 0.01, 0.8),
+	} {
+		var wg sync.WaitGroup
+		for g := uint32(0); g < 8; g++ {
+			wg.Add(1)
+			go func(g uint32) {
+				defer wg.Done()
+				n := make([]byte, 4)
+				for i := g * 1000; i < (g+1)*1000; i++ {
+					binary.BigEndian.PutUint32(n, i)
+					f.Add(n)
+					if !f.Exist(n) {
+						t.Errorf("%s: %d should Exist.", name, i)
+						return
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+
+		if f.N() != 8000 {
+			t.Errorf("%s: N got %d want 8000", name, f.N())
+		}
+		if !existRange(f, 0, 8000) {
+			t.Errorf("%s: all the elements should Exist.", name)
+		}
+	}
+}
+
+func TestSyncRemove(t *testing.T) {
+	f := bloom.NewSync(4096, 4)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				f.AddString("Boss")
+			}
+		}()
+	}
+	wg.Wait()
+
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				f.RemoveString("Boss")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if f.ExistString("Boss") {
+		t.Error("Boss should not Exist after removed as many times as added.")
+	}
+	if f.N() != 0 {
+		t.Errorf("N got %d want 0", f.N())
+	}
+}
+
+type mutexBloom struct {
+	sync.Mutex
+	bloom.Bloom
+}
+
+func (m *mutexBloom) Add(entry []byte) {
+	m.Lock()
+	m.Bloom.Add(entry)
+	m.Unlock()
+}
+
+func (m *mutexBloom) Exist(entry []byte) bool {
+	m.Lock()
+	defer m.Unlock()
+	return m.Bloom.Exist(entry)
+}
+
+func benchmarkParallel(b *testing.B, f bloom.Bloom) {
+	b.RunParallel(func(pb *testing.PB) {
+		n := make([]byte, 4)
+		i := uint32(0)
+		for pb.Next() {
+			binary.BigEndian.PutUint32(n, i)
+			if i%4 == 0 {
+				f.Add(n)
+			} else {
+				f.Exist(n)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkMutexBitParallel(b *testing.B) {
+	benchmarkParallel(b, &mutexBloom{Bloom: bloom.NewBGuess(1000000, 0.01)})
+}
+
+func BenchmarkSyncBitParallel(b *testing.B) {
+	benchmarkParallel(b, bloom.NewSyncBGuess(1000000, 0.01))
+}
+
+func BenchmarkMutexCountingParallel(b *testing.B) {
+	benchmarkParallel(b, &mutexBloom{Bloom: bloom.NewGuess(1000000, 0.01)})
+}
+
+func BenchmarkSyncCountingParallel(b *testing.B) {
+	benchmarkParallel(b, bloom.NewSyncGuess(1000000, 0.01))
+}
+
+func BenchmarkMutexScaleParallel(b *testing.B) {
+	benchmarkParallel(b, &mutexBloom{Bloom: bloom.NewS(0.01)})
+}
+
+func BenchmarkSyncScaleParallel(b *testing.B) {
+	benchmarkParallel(b, bloom.NewSyncS(0.01))
+}
@@ -0,0 +1,99 @@
+package bloom_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/andy2046/gopie/pkg/bloom"
+)
+
+// layouts are the bloom filters with the same m/k but different bit layouts.
+var layouts = []struct {
+	name string
+	new  func(n uint64, p float64) bloom.Bloom
+}{
+	{"bit", bloom.NewBGuess},
+	{"blocked", bloom.NewBlockedGuess},
+	{"partitioned", bloom.NewPartitionedGuess},
+}
+
+func TestLayoutFalsePositive(t *testing.T) {
+	const n = 100000
+	for _, p := range []float64{0.01, 0.001} {
+		for _, l := range layouts {
+			f := l.new(n, p)
+			addRange(f, 0, n)
+			if !existRange(f, 0, n) {
+				t.Fatalf("%s: all the elements should Exist.", l.name)
+			}
+
+			fp := 0
+			for i := uint32(n); i < 11*n; i++ {
+				if f.Exist(key(i)) {
+					fp++
+				}
+			}
+			rate, want := float64(fp)/(10*n), f.FalsePositive()
+			t.Logf("%s: p=%v m=%d k=%d measured=%.5f estimated=%.5f", l.name, p, f.M(), f.K(), rate, want)
+			if rate > 1.2*want+0.0001 {
+				t.Errorf("%s: false positive rate got %f want about %f", l.name, rate, want)
+			}
+		}
+	}
+}
+
+func key(i uint32) []byte {
+	n := make([]byte, 4)
+	binary.BigEndian.PutUint32(n, i)
+	return n
+}
+
+// the filters are larger than the CPU cache to show the cost of cache misses.
+const layoutN = 1 << 22
+
+func benchmarkLayoutAdd(b *testing.B, f bloom.Bloom) {
+	k := make([]byte, 8)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		binary.BigEndian.PutUint64(k, uint64(i))
+		f.Add(k)
+	}
+}
+
+func benchmarkLayoutExist(b *testing.B, f bloom.Bloom) {
+	k := make([]byte, 8)
+	for i := 0; i < layoutN; i++ {
+		binary.BigEndian.PutUint64(k, uint64(i))
+		f.Add(k)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// half of the lookups are misses.
+		binary.BigEndian.PutUint64(k, uint64(i%(2*layoutN)))
+		f.Exist(k)
+	}
+}
+
+func BenchmarkLayoutAdd(b *testing.B) {
+	for _, l := range layouts {
+		l := l
+		b.Run(l.name, func(b *testing.B) {
+			benchmarkLayoutAdd(b, l.new(layoutN, 0.01))
+		})
+	}
+}
+
+func BenchmarkLayoutExist(b *testing.B) {
+	filters := make(map[string]bloom.Bloom)
+	for _, l := range layouts {
+		l := l
+		b.Run(l.name, func(b *testing.B) {
+			f, ok := filters[l.name]
+			if !ok {
+				f = l.new(layoutN, 0.01)
+				filters[l.name] = f
+			}
+			benchmarkLayoutExist(b, f)
+		})
+	}
+}
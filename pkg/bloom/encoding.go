@@ -0,0 +1,898 @@
+package bloom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"sync/atomic"
+
+	"github.com/andy2046/bitmap"
+)
+
+/*
+   the encoding is big endian with a header followed by the variant specific body.
+
+   header:
+     magic   [4]byte "BLMF"
+     version uint8
+     variant uint8
+     k0, k1  uint64 sipHash keys
+
+   bitFilter body: m, k, n uint64, bitmap [m/8]byte
+   counting body:  m, k, n uint64, counters [m]uint16
+   scalable body:  count, n uint64, p, r, fillRatio float64, len(filterz) uint32, bitFilter body * len(filterz)
+   blocked body:   m, k, n uint64, bitmap [m/8]byte
+   partitioned body: s, k, n uint64, bitmap [k*s/8]byte, s is the size of slice bits
+   stable body:    m, k, n, p uint64, max uint8, rnd uint64, cells [m]uint8
+   aging body:     span uint64, len(filterz) uint32, (epoch uint64, bitFilter body) * len(filterz)
+
+   the goroutine-safe variants share the body of their counterparts.
+*/
+
+const (
+	encodingVersion uint8 = 1
+	maxHashes             = 1024 // maximum number of hash functions k
+	minChunkSize          = 4096 // minimum size of the buffer growth on decoding
+)
+
+const (
+	variantCounting uint8 = iota + 1
+	variantBit
+	variantScalable
+	variantSyncCounting
+	variantSyncBit
+	variantSyncScalable
+	variantBlocked
+	variantPartitioned
+	variantStable
+	variantAging
+)
+
+var (
+	magic = [4]byte{'B', 'L', 'M', 'F'}
+
+	// ErrInvalidEncoding when the data is not a valid encoded bloom filter,
+	// e.g. a different version or sipHash keys,
+	// or when the filter can not be encoded, e.g. k is 0 or larger than 1024.
+	ErrInvalidEncoding = errors.New("invalid bloom filter encoding")
+	// ErrVariantMismatch when the data is decoded into a different variant of bloom filter.
+	ErrVariantMismatch = errors.New("bloom filter variant mismatch")
+)
+
+// Unmarshal decodes the bloom filter encoded by MarshalBinary,
+// the returned CountingBloom filter can be asserted to CountingBloom.
+func Unmarshal(data []byte) (Bloom, error) {
+	r := bytes.NewReader(data)
+	bf, err := Read(r)
+	if err == io.EOF {
+		return nil, ErrInvalidEncoding
+	} else if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, ErrInvalidEncoding
+	}
+	return bf, nil
+}
+
+// Read decodes the bloom filter written by WriteTo from r.
+func Read(r io.Reader) (Bloom, error) {
+	d := newDecoder(r)
+	variant := d.header()
+	if d.err != nil {
+		return nil, d.err
+	}
+	var bf interface {
+		Bloom
+		readBody(*decoder)
+	}
+	switch variant {
+	case variantCounting:
+		bf = &bloomFilter{}
+	case variantBit:
+		bf = &bloomFilterBit{}
+	case variantScalable:
+		bf = &scalableBloomFilter{}
+	case variantSyncCounting:
+		bf = &syncBloomFilter{}
+	case variantSyncBit:
+		bf = &syncBloomFilterBit{}
+	case variantSyncScalable:
+		bf = &syncScalableBloomFilter{}
+	case variantBlocked:
+		bf = &blockedBloomFilter{}
+	case variantPartitioned:
+		bf = &partitionedBloomFilter{}
+	case variantStable:
+		bf = &stableBloomFilter{}
+	case variantAging:
+		bf = &agingBloomFilter{}
+	default:
+		return nil, ErrInvalidEncoding
+	}
+	bf.readBody(d)
+	if d.err != nil {
+		return nil, d.err
+	}
+	return bf, nil
+}
+
+// encoder writes big endian values to w, the first error is kept in err.
+type encoder struct {
+	w   io.Writer
+	n   int64
+	err error
+	buf [8]byte
+}
+
+func (e *encoder) write(p []byte) {
+	if e.err != nil {
+		return
+	}
+	n, err := e.w.Write(p)
+	e.n += int64(n)
+	e.err = err
+}
+
+func (e *encoder) uint8(v uint8) {
+	e.buf[0] = v
+	e.write(e.buf[:1])
+}
+
+func (e *encoder) uint32(v uint32) {
+	binary.BigEndian.PutUint32(e.buf[:4], v)
+	e.write(e.buf[:4])
+}
+
+func (e *encoder) uint64(v uint64) {
+	binary.BigEndian.PutUint64(e.buf[:], v)
+	e.write(e.buf[:])
+}
+
+// hashes writes the number of hash functions k,
+// k must be in range [1, maxHashes] so that the filter can be decoded.
+func (e *encoder) hashes(k uint64) {
+	if e.err == nil && (k < 1 || k > maxHashes) {
+		e.err = ErrInvalidEncoding
+	}
+	e.uint64(k)
+}
+
+func (e *encoder) float64(v float64) {
+	e.uint64(math.Float64bits(v))
+}
+
+func (e *encoder) header(variant uint8) {
+	e.write(magic[:])
+	e.uint8(encodingVersion)
+	e.uint8(variant)
+	e.uint64(k0)
+	e.uint64(k1)
+}
+
+// words writes the bits of ws packed in bytes.
+func (e *encoder) words(ws []uint64) {
+	chunk := make([]byte, 0, 4096)
+	for _, w := range ws {
+		for j := uint(0); j < 64; j += 8 {
+			chunk = append(chunk, byte(w>>j))
+		}
+		if len(chunk) == cap(chunk) {
+			e.write(chunk)
+			chunk = chunk[:0]
+		}
+	}
+	e.write(chunk)
+}
+
+// counters writes the counters as uint16.
+func (e *encoder) counters(n int, get func(i int) uint16) {
+	chunk := make([]byte, 0, 4096)
+	for i := 0; i < n; i++ {
+		c := get(i)
+		chunk = append(chunk, byte(c>>8), byte(c))
+		if len(chunk) == cap(chunk) {
+			e.write(chunk)
+			chunk = chunk[:0]
+		}
+	}
+	e.write(chunk)
+}
+
+// decoder reads big endian values from r, the first error is kept in err.
+type decoder struct {
+	r     io.Reader
+	n     int64
+	avail int64 // number of bytes in r if known, -1 otherwise
+	err   error
+	buf   [8]byte
+}
+
+func newDecoder(r io.Reader) *decoder {
+	d := &decoder{r: r, avail: -1}
+	if l, ok := r.(interface{ Len() int }); ok {
+		d.avail = int64(l.Len())
+	}
+	return d
+}
+
+func (d *decoder) read(p []byte) {
+	if d.err != nil {
+		return
+	}
+	n, err := io.ReadFull(d.r, p)
+	d.n += int64(n)
+	if err == io.ErrUnexpectedEOF || (err == io.EOF && d.n > 0) {
+		err = ErrInvalidEncoding
+	}
+	d.err = err
+}
+
+func (d *decoder) uint8() uint8 {
+	d.read(d.buf[:1])
+	return d.buf[0]
+}
+
+func (d *decoder) uint32() uint32 {
+	d.read(d.buf[:4])
+	return binary.BigEndian.Uint32(d.buf[:4])
+}
+
+func (d *decoder) uint64() uint64 {
+	d.read(d.buf[:])
+	return binary.BigEndian.Uint64(d.buf[:])
+}
+
+func (d *decoder) float64() float64 {
+	return math.Float64frombits(d.uint64())
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = ErrInvalidEncoding
+	}
+}
+
+// header returns the variant after validating the header.
+func (d *decoder) header() uint8 {
+	var m [4]byte
+	d.read(m[:])
+	version, variant := d.uint8(), d.uint8()
+	key0, key1 := d.uint64(), d.uint64()
+	if m != magic || version != encodingVersion || key0 != k0 || key1 != k1 {
+		d.fail()
+	}
+	return variant
+}
+
+// expect reads the header and checks the variant.
+func (d *decoder) expect(variant uint8) {
+	if v := d.header(); d.err == nil && v != variant {
+		d.err = ErrVariantMismatch
+	}
+}
+
+// need fails if r is known to have less than size bytes left.
+func (d *decoder) need(size uint64) {
+	if d.avail >= 0 && size > uint64(d.avail-d.n) {
+		d.fail()
+	}
+}
+
+// bytes reads size bytes, the buffer grows as the bytes arrive
+// so that a corrupt size can not allocate much more than the input.
+func (d *decoder) bytes(size uint64) []byte {
+	d.need(size)
+	var p []byte
+	for uint64(len(p)) < size && d.err == nil {
+		chunk := size - uint64(len(p))
+		if grow := uint64(len(p)) + minChunkSize; chunk > grow {
+			chunk = grow
+		}
+		l := len(p)
+		p = append(p, make([]byte, chunk)...)
+		d.read(p[l:])
+	}
+	return p
+}
+
+// size reads m and returns the exponent of m,
+// m must be a power of two within the range of adjustM.
+func (d *decoder) size(max uint64) (m uint64, exponent uint8) {
+	m = d.uint64()
+	if d.err != nil {
+		return
+	}
+	if m < 512 || m > max || m&(m-1) != 0 {
+		d.fail()
+		return
+	}
+	for x := m; x > 1; x >>= 1 {
+		exponent++
+	}
+	return
+}
+
+// words reads size bits packed in bytes into words.
+func (d *decoder) words(size uint64) []uint64 {
+	p := d.bytes(size / 8)
+	if d.err != nil {
+		return nil
+	}
+	ws := make([]uint64, size/64)
+	for i := range ws {
+		ws[i] = binary.LittleEndian.Uint64(p[8*i:])
+	}
+	return ws
+}
+
+// counters reads n counters as uint16.
+func (d *decoder) counters(n uint64) []uint16 {
+	p := d.bytes(2 * n)
+	if d.err != nil {
+		return nil
+	}
+	cs := make([]uint16, n)
+	for i := range cs {
+		cs[i] = binary.BigEndian.Uint16(p[2*i:])
+	}
+	return cs
+}
+
+// hashes reads the number of hash functions k, which is at most m.
+func (d *decoder) hashes(m uint64) uint64 {
+	k := d.uint64()
+	if d.err == nil && (k < 1 || k > maxHashes || k > m) {
+		d.fail()
+	}
+	return k
+}
+
+// body reads m, k, n of the filter body.
+func (d *decoder) body(max uint64) (m, k, n uint64, exponent uint8) {
+	m, exponent = d.size(max)
+	k = d.hashes(m)
+	n = d.uint64()
+	return
+}
+
+func marshal(w io.WriterTo) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := w.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func unmarshal(r io.ReaderFrom, data []byte) error {
+	// ReadFrom checks the sizes in the header against the length of bytes.Reader.
+	br := bytes.NewReader(data)
+	if _, err := r.ReadFrom(br); err == io.EOF {
+		return ErrInvalidEncoding
+	} else if err != nil {
+		return err
+	}
+	if br.Len() != 0 {
+		return ErrInvalidEncoding
+	}
+	return nil
+}
+
+func (bf *bloomFilter) WriteTo(w io.Writer) (int64, error) {
+	e := &encoder{w: w}
+	e.header(variantCounting)
+	e.uint64(bf.m + 1)
+	e.hashes(bf.k)
+	e.uint64(bf.n)
+	e.counters(len(bf.bitmap), func(i int) uint16 { return bf.bitmap[i] })
+	return e.n, e.err
+}
+
+func (bf *bloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	d := newDecoder(r)
+	d.expect(variantCounting)
+	bf.readBody(d)
+	return d.n, d.err
+}
+
+func (bf *bloomFilter) readBody(d *decoder) {
+	m, k, n, exponent := d.body(maxCountingBloomSize)
+	if d.err != nil {
+		return
+	}
+	bitmap := d.counters(m)
+	if d.err != nil {
+		return
+	}
+	*bf = bloomFilter{
+		bitmap: bitmap,
+		m:      m - 1,
+		k:      k,
+		n:      n,
+		shift:  64 - exponent,
+	}
+}
+
+func (bf *bloomFilter) MarshalBinary() ([]byte, error) {
+	return marshal(bf)
+}
+
+func (bf *bloomFilter) UnmarshalBinary(data []byte) error {
+	return unmarshal(bf, data)
+}
+
+func (bf *bloomFilterBit) WriteTo(w io.Writer) (int64, error) {
+	e := &encoder{w: w}
+	e.header(variantBit)
+	bf.writeBody(e)
+	return e.n, e.err
+}
+
+func (bf *bloomFilterBit) writeBody(e *encoder) {
+	e.uint64(bf.m + 1)
+	e.hashes(bf.k)
+	e.uint64(bf.n)
+	e.words(bf.bitmap)
+}
+
+func (bf *bloomFilterBit) ReadFrom(r io.Reader) (int64, error) {
+	d := newDecoder(r)
+	d.expect(variantBit)
+	bf.readBody(d)
+	return d.n, d.err
+}
+
+func (bf *bloomFilterBit) readBody(d *decoder) {
+	m, k, n, exponent := d.body(bitmap.MaxBitmapSize)
+	if d.err != nil {
+		return
+	}
+	ws := d.words(m)
+	if d.err != nil {
+		return
+	}
+	*bf = bloomFilterBit{
+		bitmap: ws,
+		m:      m - 1,
+		k:      k,
+		n:      n,
+		shift:  64 - exponent,
+	}
+}
+
+func (bf *bloomFilterBit) MarshalBinary() ([]byte, error) {
+	return marshal(bf)
+}
+
+func (bf *bloomFilterBit) UnmarshalBinary(data []byte) error {
+	return unmarshal(bf, data)
+}
+
+func (bf *scalableBloomFilter) WriteTo(w io.Writer) (int64, error) {
+	e := &encoder{w: w}
+	e.header(variantScalable)
+	e.uint64(bf.count)
+	e.uint64(bf.n)
+	e.float64(bf.p)
+	e.float64(bf.r)
+	e.float64(bf.fillRatio)
+	e.uint32(uint32(len(bf.filterz)))
+	for _, f := range bf.filterz {
+		f.writeBody(e)
+	}
+	return e.n, e.err
+}
+
+func (bf *scalableBloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	d := newDecoder(r)
+	d.expect(variantScalable)
+	bf.readBody(d)
+	return d.n, d.err
+}
+
+func (bf *scalableBloomFilter) readBody(d *decoder) {
+	count, n := d.uint64(), d.uint64()
+	p, r, fr := d.float64(), d.float64(), d.float64()
+	size := d.uint32()
+	if d.err != nil {
+		return
+	}
+	if size == 0 {
+		d.fail()
+		return
+	}
+	// the chain is bounded by the bytes read rather than the size in the header.
+	var filterz []*bloomFilterBit
+	for i := uint32(0); i < size; i++ {
+		f := &bloomFilterBit{}
+		f.readBody(d)
+		if d.err != nil {
+			return
+		}
+		filterz = append(filterz, f)
+	}
+	*bf = scalableBloomFilter{
+		filterz:   filterz,
+		count:     count,
+		n:         n,
+		p:         p,
+		r:         r,
+		fillRatio: fr,
+	}
+}
+
+func (bf *scalableBloomFilter) MarshalBinary() ([]byte, error) {
+	return marshal(bf)
+}
+
+func (bf *scalableBloomFilter) UnmarshalBinary(data []byte) error {
+	return unmarshal(bf, data)
+}
+
+func (bf *syncBloomFilter) WriteTo(w io.Writer) (int64, error) {
+	e := &encoder{w: w}
+	e.header(variantSyncCounting)
+	e.uint64(bf.m + 1)
+	e.hashes(bf.k)
+	e.uint64(bf.N())
+	e.counters(len(bf.bitmap), func(i int) uint16 {
+		return uint16(atomic.LoadUint32(&bf.bitmap[i]))
+	})
+	return e.n, e.err
+}
+
+// ReadFrom is not safe for concurrent use.
+func (bf *syncBloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	d := newDecoder(r)
+	d.expect(variantSyncCounting)
+	bf.readBody(d)
+	return d.n, d.err
+}
+
+func (bf *syncBloomFilter) readBody(d *decoder) {
+	m, k, n, exponent := d.body(maxCountingBloomSize)
+	if d.err != nil {
+		return
+	}
+	counters := d.counters(m)
+	if d.err != nil {
+		return
+	}
+	bitmap := make([]uint32, m)
+	for i, c := range counters {
+		bitmap[i] = uint32(c)
+	}
+	*bf = syncBloomFilter{
+		bitmap: bitmap,
+		m:      m - 1,
+		k:      k,
+		n:      n,
+		shift:  64 - exponent,
+	}
+}
+
+func (bf *syncBloomFilter) MarshalBinary() ([]byte, error) {
+	return marshal(bf)
+}
+
+// UnmarshalBinary is not safe for concurrent use.
+func (bf *syncBloomFilter) UnmarshalBinary(data []byte) error {
+	return unmarshal(bf, data)
+}
+
+func (bf *syncBloomFilterBit) WriteTo(w io.Writer) (int64, error) {
+	e := &encoder{w: w}
+	e.header(variantSyncBit)
+	bf.writeBody(e)
+	return e.n, e.err
+}
+
+func (bf *syncBloomFilterBit) writeBody(e *encoder) {
+	e.uint64(bf.m + 1)
+	e.hashes(bf.k)
+	e.uint64(bf.N())
+	ws := make([]uint64, len(bf.bitmap))
+	for i := range ws {
+		ws[i] = atomic.LoadUint64(&bf.bitmap[i])
+	}
+	e.words(ws)
+}
+
+// ReadFrom is not safe for concurrent use.
+func (bf *syncBloomFilterBit) ReadFrom(r io.Reader) (int64, error) {
+	d := newDecoder(r)
+	d.expect(variantSyncBit)
+	bf.readBody(d)
+	return d.n, d.err
+}
+
+func (bf *syncBloomFilterBit) readBody(d *decoder) {
+	m, k, n, exponent := d.body(bitmap.MaxBitmapSize)
+	if d.err != nil {
+		return
+	}
+	ws := d.words(m)
+	if d.err != nil {
+		return
+	}
+	*bf = syncBloomFilterBit{
+		bitmap: ws,
+		m:      m - 1,
+		k:      k,
+		n:      n,
+		shift:  64 - exponent,
+	}
+}
+
+func (bf *syncBloomFilterBit) MarshalBinary() ([]byte, error) {
+	return marshal(bf)
+}
+
+// UnmarshalBinary is not safe for concurrent use.
+func (bf *syncBloomFilterBit) UnmarshalBinary(data []byte) error {
+	return unmarshal(bf, data)
+}
+
+func (bf *syncScalableBloomFilter) WriteTo(w io.Writer) (int64, error) {
+	bf.mu.RLock()
+	defer bf.mu.RUnlock()
+
+	e := &encoder{w: w}
+	e.header(variantSyncScalable)
+	e.uint64(bf.N())
+	e.uint64(bf.n)
+	e.float64(bf.p)
+	e.float64(bf.r)
+	e.float64(bf.fillRatio)
+	e.uint32(uint32(len(bf.filterz)))
+	for _, f := range bf.filterz {
+		f.writeBody(e)
+	}
+	return e.n, e.err
+}
+
+func (bf *syncScalableBloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	d := newDecoder(r)
+	d.expect(variantSyncScalable)
+	bf.readBody(d)
+	return d.n, d.err
+}
+
+func (bf *syncScalableBloomFilter) readBody(d *decoder) {
+	count, n := d.uint64(), d.uint64()
+	p, r, fr := d.float64(), d.float64(), d.float64()
+	size := d.uint32()
+	if d.err != nil {
+		return
+	}
+	if size == 0 {
+		d.fail()
+		return
+	}
+	// the chain is bounded by the bytes read rather than the size in the header.
+	var filterz []*syncBloomFilterBit
+	for i := uint32(0); i < size; i++ {
+		f := &syncBloomFilterBit{}
+		f.readBody(d)
+		if d.err != nil {
+			return
+		}
+		filterz = append(filterz, f)
+	}
+
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+	bf.filterz = filterz
+	atomic.StoreUint64(&bf.count, count)
+	bf.n, bf.p, bf.r, bf.fillRatio = n, p, r, fr
+}
+
+func (bf *syncScalableBloomFilter) MarshalBinary() ([]byte, error) {
+	return marshal(bf)
+}
+
+func (bf *syncScalableBloomFilter) UnmarshalBinary(data []byte) error {
+	return unmarshal(bf, data)
+}
+
+func (bf *blockedBloomFilter) WriteTo(w io.Writer) (int64, error) {
+	e := &encoder{w: w}
+	e.header(variantBlocked)
+	e.uint64(bf.M())
+	e.hashes(bf.k)
+	e.uint64(bf.n)
+	e.words(bf.bitmap)
+	return e.n, e.err
+}
+
+func (bf *blockedBloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	d := newDecoder(r)
+	d.expect(variantBlocked)
+	bf.readBody(d)
+	return d.n, d.err
+}
+
+func (bf *blockedBloomFilter) readBody(d *decoder) {
+	m, k, n, _ := d.body(maxCountingBloomSize)
+	if d.err != nil {
+		return
+	}
+	if k > blockBits {
+		d.fail()
+		return
+	}
+	ws := d.words(m)
+	if d.err != nil {
+		return
+	}
+	*bf = blockedBloomFilter{
+		bitmap: ws,
+		k:      k,
+		n:      n,
+		blocks: m / blockBits,
+	}
+}
+
+func (bf *blockedBloomFilter) MarshalBinary() ([]byte, error) {
+	return marshal(bf)
+}
+
+func (bf *blockedBloomFilter) UnmarshalBinary(data []byte) error {
+	return unmarshal(bf, data)
+}
+
+func (bf *partitionedBloomFilter) WriteTo(w io.Writer) (int64, error) {
+	e := &encoder{w: w}
+	e.header(variantPartitioned)
+	e.uint64(bf.s)
+	e.hashes(bf.k)
+	e.uint64(bf.n)
+	e.words(bf.bitmap)
+	return e.n, e.err
+}
+
+func (bf *partitionedBloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	d := newDecoder(r)
+	d.expect(variantPartitioned)
+	bf.readBody(d)
+	return d.n, d.err
+}
+
+func (bf *partitionedBloomFilter) readBody(d *decoder) {
+	s := d.uint64()
+	k, n := d.hashes(s), d.uint64()
+	if d.err != nil {
+		return
+	}
+	if s < 64 || s > maxSliceSize || s%64 != 0 || k > bitmap.MaxBitmapSize/s {
+		d.fail()
+		return
+	}
+	ws := d.words(k * s)
+	if d.err != nil {
+		return
+	}
+	*bf = partitionedBloomFilter{
+		bitmap: ws,
+		k:      k,
+		n:      n,
+		s:      s,
+	}
+}
+
+func (bf *partitionedBloomFilter) MarshalBinary() ([]byte, error) {
+	return marshal(bf)
+}
+
+func (bf *partitionedBloomFilter) UnmarshalBinary(data []byte) error {
+	return unmarshal(bf, data)
+}
+
+func (bf *stableBloomFilter) WriteTo(w io.Writer) (int64, error) {
+	e := &encoder{w: w}
+	e.header(variantStable)
+	e.uint64(bf.m + 1)
+	e.hashes(bf.k)
+	e.uint64(bf.n)
+	e.uint64(bf.p)
+	e.uint8(bf.max)
+	e.uint64(bf.rnd)
+	e.write(bf.cells)
+	return e.n, e.err
+}
+
+func (bf *stableBloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	d := newDecoder(r)
+	d.expect(variantStable)
+	bf.readBody(d)
+	return d.n, d.err
+}
+
+func (bf *stableBloomFilter) readBody(d *decoder) {
+	m, k, n, exponent := d.body(maxCountingBloomSize)
+	p, max, rnd := d.uint64(), d.uint8(), d.uint64()
+	if d.err != nil {
+		return
+	}
+	if p < 1 || p > m || max < 1 || rnd == 0 {
+		d.fail()
+		return
+	}
+	cells := d.bytes(m)
+	if d.err != nil {
+		return
+	}
+	*bf = stableBloomFilter{
+		cells: cells,
+		m:     m - 1,
+		k:     k,
+		n:     n,
+		p:     p,
+		max:   max,
+		shift: 64 - exponent,
+		rnd:   rnd,
+	}
+}
+
+func (bf *stableBloomFilter) MarshalBinary() ([]byte, error) {
+	return marshal(bf)
+}
+
+func (bf *stableBloomFilter) UnmarshalBinary(data []byte) error {
+	return unmarshal(bf, data)
+}
+
+func (bf *agingBloomFilter) WriteTo(w io.Writer) (int64, error) {
+	e := &encoder{w: w}
+	e.header(variantAging)
+	e.uint64(bf.span)
+	e.uint32(uint32(len(bf.filterz)))
+	for i, f := range bf.filterz {
+		e.uint64(bf.epochs[i])
+		f.writeBody(e)
+	}
+	return e.n, e.err
+}
+
+func (bf *agingBloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	d := newDecoder(r)
+	d.expect(variantAging)
+	bf.readBody(d)
+	return d.n, d.err
+}
+
+func (bf *agingBloomFilter) readBody(d *decoder) {
+	span, size := d.uint64(), d.uint32()
+	if d.err != nil {
+		return
+	}
+	if span == 0 || size != agingGenerations+1 {
+		d.fail()
+		return
+	}
+	filterz := make([]*bloomFilterBit, size)
+	epochs := make([]uint64, size)
+	for i := range filterz {
+		epochs[i] = d.uint64()
+		filterz[i] = &bloomFilterBit{}
+		filterz[i].readBody(d)
+		if d.err != nil {
+			return
+		}
+	}
+	*bf = agingBloomFilter{
+		filterz: filterz,
+		epochs:  epochs,
+		span:    span,
+	}
+}
+
+func (bf *agingBloomFilter) MarshalBinary() ([]byte, error) {
+	return marshal(bf)
+}
+
+func (bf *agingBloomFilter) UnmarshalBinary(data []byte) error {
+	return unmarshal(bf, data)
+}
@@ -0,0 +1,194 @@
+package bloom_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/andy2046/gopie/pkg/bloom"
+)
+
+func newFilters() map[string]bloom.Bloom {
+	filters := map[string]bloom.Bloom{
+		"counting": bloom.NewGuess(1000, 0.01),
+		"bit":      bloom.NewBGuess(1000, 0.01),
+		"scalable": bloom.NewSGuess(100, 0.01, 0.8),
+
+		"blocked":     bloom.NewBlockedGuess(1000, 0.01),
+		"partitioned": bloom.NewPartitionedGuess(1000, 0.01),
+		"stable":      bloom.NewStableGuess(10000, 0.01),
+		"aging":       bloom.NewAging(time.Hour, 1000, 0.01),
+
+		"sync counting": bloom.NewSyncGuess(1000, 0.01),
+		"sync bit":      bloom.NewSyncBGuess(1000, 0.01),
+		"sync scalable": bloom.NewSyncSGuess(100, 0.01, 0.8),
+	}
+	for _, f := range filters {
+		for i := uint32(0); i < 1000; i++ {
+			n := make([]byte, 4)
+			binary.BigEndian.PutUint32(n, i)
+			f.Add(n)
+		}
+	}
+	return filters
+}
+
+func TestMarshalBinary(t *testing.T) {
+	for name, f := range newFilters() {
+		data, err := f.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		f2, err := bloom.Unmarshal(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if f2.M() != f.M() || f2.K() != f.K() || f2.N() != f.N() {
+			t.Errorf("%s: m/k/n got %d/%d/%d want %d/%d/%d", name, f2.M(), f2.K(), f2.N(), f.M(), f.K(), f.N())
+		}
+		for i := uint32(0); i < 2000; i++ {
+			n := make([]byte, 4)
+			binary.BigEndian.PutUint32(n, i)
+			if f.Exist(n) != f2.Exist(n) {
+				t.Fatalf("%s: Exist(%d) mismatch", name, i)
+			}
+		}
+
+		data2, err := f2.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(data, data2) {
+			t.Errorf("%s: round trip is not byte-for-byte", name)
+		}
+
+		// decoding into an existing filter of the same variant replaces it.
+		f3 := newFilters()[name]
+		f3.Clear()
+		if err := f3.UnmarshalBinary(data); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if data3, _ := f3.MarshalBinary(); !bytes.Equal(data, data3) {
+			t.Errorf("%s: UnmarshalBinary mismatch", name)
+		}
+	}
+}
+
+func TestWriteToReadFrom(t *testing.T) {
+	filters := newFilters()
+	var buf bytes.Buffer
+	names := []string{"counting", "bit", "scalable", "blocked", "partitioned", "stable", "aging"}
+	for _, name := range names {
+		n, err := filters[name].WriteTo(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if data, _ := filters[name].MarshalBinary(); int64(len(data)) != n {
+			t.Errorf("%s: WriteTo got %d bytes want %d", name, n, len(data))
+		}
+	}
+
+	// the filters written one after another are read back in order.
+	for _, name := range names {
+		f, err := bloom.Read(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if f.N() != filters[name].N() || f.M() != filters[name].M() {
+			t.Errorf("%s: Read mismatch", name)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Errorf("%d bytes left", buf.Len())
+	}
+
+	data, _ := filters["bit"].MarshalBinary()
+	f := bloom.NewB(1000, 4)
+	n, err := f.ReadFrom(bytes.NewReader(data))
+	if err != nil || n != int64(len(data)) {
+		t.Errorf("ReadFrom got %d %v", n, err)
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	filters := newFilters()
+	data, _ := filters["bit"].MarshalBinary()
+
+	for name, d := range map[string][]byte{
+		"empty":     {},
+		"truncated": data[:len(data)-1],
+		"trailing":  append(append([]byte{}, data...), 0),
+		"magic":     append([]byte("XXXX"), data[4:]...),
+	} {
+		if _, err := bloom.Unmarshal(d); err != bloom.ErrInvalidEncoding {
+			t.Errorf("%s: got %v want %v", name, err, bloom.ErrInvalidEncoding)
+		}
+	}
+
+	if err := filters["counting"].UnmarshalBinary(data); err != bloom.ErrVariantMismatch {
+		t.Errorf("got %v want %v", err, bloom.ErrVariantMismatch)
+	}
+}
+
+func TestMarshalLongChain(t *testing.T) {
+	f := bloom.NewSGuess(100, 0.01, 0.9)
+	for i := uint32(0); i < 20000; i++ {
+		n := make([]byte, 4)
+		binary.BigEndian.PutUint32(n, i)
+		f.Add(n)
+	}
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the chain size follows the header, count, n, p, r and fillRatio.
+	if size := binary.BigEndian.Uint32(data[62:]); size <= 64 {
+		t.Fatalf("chain size got %d want more than 64", size)
+	}
+
+	f2, err := bloom.Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f2.M() != f.M() || f2.N() != f.N() {
+		t.Errorf("m/n got %d/%d want %d/%d", f2.M(), f2.N(), f.M(), f.N())
+	}
+	for i := uint32(0); i < 20000; i++ {
+		n := make([]byte, 4)
+		binary.BigEndian.PutUint32(n, i)
+		if !f2.Exist(n) {
+			t.Fatalf("Exist(%d) should be true", i)
+		}
+	}
+}
+
+func TestUnmarshalCorruptHeader(t *testing.T) {
+	data, _ := bloom.NewGuess(1000, 0.01).MarshalBinary()
+	// the body of the counting filter starts with m, k, n after the 22 bytes header.
+	patch := func(offset int, v uint64) []byte {
+		d := append([]byte{}, data[:60]...)
+		binary.BigEndian.PutUint64(d[offset:], v)
+		return d
+	}
+
+	for name, d := range map[string][]byte{
+		"huge m": patch(22, 1<<36),
+		"zero k": patch(30, 0),
+		"huge k": patch(30, 1<<40),
+	} {
+		if _, err := bloom.Unmarshal(d); err != bloom.ErrInvalidEncoding {
+			t.Errorf("%s: Unmarshal got %v want %v", name, err, bloom.ErrInvalidEncoding)
+		}
+		// the reader of unknown length fails once the bytes run out.
+		if _, err := bloom.Read(io.MultiReader(bytes.NewReader(d))); err != bloom.ErrInvalidEncoding {
+			t.Errorf("%s: Read got %v want %v", name, err, bloom.ErrInvalidEncoding)
+		}
+	}
+
+	if _, err := bloom.NewB(1024, 0).MarshalBinary(); err != bloom.ErrInvalidEncoding {
+		t.Errorf("zero k: MarshalBinary got %v want %v", err, bloom.ErrInvalidEncoding)
+	}
+}
@@ -0,0 +1,353 @@
+package bloom
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"sync/atomic"
+)
+
+type (
+	// wordFilter is the bloom filter with the bits packed in words,
+	// which is combined word by word.
+	wordFilter interface {
+		Bloom
+		// words returns the words of the filter, which must not be modified.
+		words() []uint64
+		// compatible returns true if f is of the same variant created with the same m/k.
+		compatible(f Bloom) bool
+		// withWords returns a new filter of the same variant and m/k with the words ws.
+		withWords(ws []uint64, n uint64) Bloom
+	}
+
+	// counterFilter is the counting bloom filter,
+	// which is combined counter by counter.
+	counterFilter interface {
+		CountingBloom
+		// counters returns the counters of the filter, which must not be modified.
+		counters() []uint16
+		// compatible returns true if f is of the same variant created with the same m/k.
+		compatible(f Bloom) bool
+		// withCounters returns a new filter of the same variant and m/k with the counters cs.
+		withCounters(cs []uint16, n uint64) CountingBloom
+	}
+)
+
+var (
+	// ErrIncompatible when the bloom filters differ in variant, m, k or sipHash keys.
+	// The sipHash keys are fixed in this package and verified on decoding,
+	// so the filters built by any process are compatible as long as m and k match.
+	ErrIncompatible = errors.New("incompatible bloom filters")
+	// ErrUnsupported when the operation is not supported by the variant of bloom filter.
+	ErrUnsupported = errors.New("operation not supported by the bloom filter")
+)
+
+// Union returns a new bloom filter containing the elements of all the filters,
+// which must be of the same variant created with the same m/k.
+// The standard, counting, scalable, blocked and partitioned bloom filters
+// and their goroutine-safe variants are supported,
+// the stable and aging bloom filters are not supported.
+// For the counting bloom filter, the counters of the result are the maximum of the counters,
+// use Merge to sum them up instead.
+// For the scalable bloom filter, the result chains all the filters.
+// The N of the result is estimated from the fill ratio, except for the scalable bloom filter
+// which sums up the N of all the filters.
+func Union(filters ...Bloom) (Bloom, error) {
+	if len(filters) == 0 {
+		return nil, ErrIncompatible
+	}
+
+	switch f := filters[0].(type) {
+	case wordFilter:
+		return combineWords(f, filters, func(a, b uint64) uint64 { return a | b })
+	case counterFilter:
+		return combineCounters(f, filters, func(a, b uint16) uint16 {
+			if a > b {
+				return a
+			}
+			return b
+		})
+	case *scalableBloomFilter:
+		return unionScalable(f, filters)
+	case *syncScalableBloomFilter:
+		return unionSyncScalable(f, filters)
+	}
+	return nil, ErrUnsupported
+}
+
+// Intersect returns a new bloom filter containing the elements in all the filters,
+// which must be of the same variant created with the same m/k.
+// The standard, counting, blocked and partitioned bloom filters
+// and their goroutine-safe variants are supported,
+// the scalable, stable and aging bloom filters are not supported.
+// For the counting bloom filter, the counters of the result are the minimum of the counters.
+// The N of the result is estimated from the fill ratio,
+// the false positive rate of the result is at least the one of the filter with the most elements.
+func Intersect(filters ...Bloom) (Bloom, error) {
+	if len(filters) == 0 {
+		return nil, ErrIncompatible
+	}
+
+	switch f := filters[0].(type) {
+	case wordFilter:
+		return combineWords(f, filters, func(a, b uint64) uint64 { return a & b })
+	case counterFilter:
+		return combineCounters(f, filters, func(a, b uint16) uint16 {
+			if a < b {
+				return a
+			}
+			return b
+		})
+	}
+	return nil, ErrUnsupported
+}
+
+// Merge returns a new counting bloom filter with the counters summed up from all the filters,
+// which must be of the same variant created with the same m/k.
+// Unlike Union, the elements added to several filters can be removed from the result as many times,
+// the N of the result is the sum of the N of all the filters.
+func Merge(filters ...CountingBloom) (CountingBloom, error) {
+	if len(filters) == 0 {
+		return nil, ErrIncompatible
+	}
+
+	first, ok := filters[0].(counterFilter)
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	cs := append([]uint16(nil), first.counters()...)
+	n := first.N()
+	for _, f := range filters[1:] {
+		if !first.compatible(f) {
+			return nil, ErrIncompatible
+		}
+		for i, c := range f.(counterFilter).counters() {
+			if cs[i] > maxCounter-c {
+				cs[i] = maxCounter
+			} else {
+				cs[i] += c
+			}
+		}
+		n += f.N()
+	}
+	return first.withCounters(cs, n), nil
+}
+
+func combineWords(first wordFilter, filters []Bloom, op func(a, b uint64) uint64) (Bloom, error) {
+	ws := append([]uint64(nil), first.words()...)
+	for _, f := range filters[1:] {
+		if !first.compatible(f) {
+			return nil, ErrIncompatible
+		}
+		for i, w := range f.(wordFilter).words() {
+			ws[i] = op(ws[i], w)
+		}
+	}
+	var x uint64
+	for _, w := range ws {
+		x += uint64(bits.OnesCount64(w))
+	}
+	return first.withWords(ws, estimateN(first.M(), first.K(), x)), nil
+}
+
+func combineCounters(first counterFilter, filters []Bloom, op func(a, b uint16) uint16) (Bloom, error) {
+	cs := append([]uint16(nil), first.counters()...)
+	for _, f := range filters[1:] {
+		if !first.compatible(f) {
+			return nil, ErrIncompatible
+		}
+		for i, c := range f.(counterFilter).counters() {
+			cs[i] = op(cs[i], c)
+		}
+	}
+	var x uint64
+	for _, c := range cs {
+		if c > 0 {
+			x++
+		}
+	}
+	return first.withCounters(cs, estimateN(first.M(), first.K(), x)), nil
+}
+
+func unionScalable(first *scalableBloomFilter, filters []Bloom) (Bloom, error) {
+	r := &scalableBloomFilter{
+		n:         first.n,
+		p:         first.p,
+		r:         first.r,
+		fillRatio: first.fillRatio,
+	}
+	for _, f := range filters {
+		bf, ok := f.(*scalableBloomFilter)
+		if !ok || bf.n != first.n || bf.p != first.p || bf.r != first.r {
+			return nil, ErrIncompatible
+		}
+		for _, sub := range bf.filterz {
+			r.filterz = append(r.filterz, sub.withWords(
+				append([]uint64(nil), sub.bitmap...), sub.n).(*bloomFilterBit))
+		}
+		r.count += bf.count
+	}
+	return r, nil
+}
+
+func unionSyncScalable(first *syncScalableBloomFilter, filters []Bloom) (Bloom, error) {
+	r := &syncScalableBloomFilter{
+		n:         first.n,
+		p:         first.p,
+		r:         first.r,
+		fillRatio: first.fillRatio,
+	}
+	for _, f := range filters {
+		bf, ok := f.(*syncScalableBloomFilter)
+		if !ok || bf.n != first.n || bf.p != first.p || bf.r != first.r {
+			return nil, ErrIncompatible
+		}
+		bf.mu.RLock()
+		for _, sub := range bf.filterz {
+			r.filterz = append(r.filterz, sub.withWords(sub.words(), sub.N()).(*syncBloomFilterBit))
+		}
+		bf.mu.RUnlock()
+		r.count += bf.N()
+	}
+	return r, nil
+}
+
+// estimateN estimates the number of elements from the number of bits set x,
+// n = -m / k * ln(1 - x / m).
+func estimateN(m, k, x uint64) uint64 {
+	if k == 0 || x == 0 {
+		return 0
+	}
+	if x >= m {
+		// the filter is full, n is at least m / k * ln(m).
+		return uint64(math.Ceil(float64(m) / float64(k) * math.Log(float64(m))))
+	}
+	return uint64(math.Round(-float64(m) / float64(k) * math.Log(1-float64(x)/float64(m))))
+}
+
+func (bf *bloomFilterBit) words() []uint64 {
+	return bf.bitmap
+}
+
+func (bf *bloomFilterBit) compatible(f Bloom) bool {
+	o, ok := f.(*bloomFilterBit)
+	return ok && o.m == bf.m && o.k == bf.k
+}
+
+func (bf *bloomFilterBit) withWords(ws []uint64, n uint64) Bloom {
+	return &bloomFilterBit{
+		bitmap: ws,
+		m:      bf.m,
+		k:      bf.k,
+		n:      n,
+		shift:  bf.shift,
+	}
+}
+
+// words returns a copy of the words loaded atomically.
+func (bf *syncBloomFilterBit) words() []uint64 {
+	ws := make([]uint64, len(bf.bitmap))
+	for i := range ws {
+		ws[i] = atomic.LoadUint64(&bf.bitmap[i])
+	}
+	return ws
+}
+
+func (bf *syncBloomFilterBit) compatible(f Bloom) bool {
+	o, ok := f.(*syncBloomFilterBit)
+	return ok && o.m == bf.m && o.k == bf.k
+}
+
+func (bf *syncBloomFilterBit) withWords(ws []uint64, n uint64) Bloom {
+	return &syncBloomFilterBit{
+		bitmap: ws,
+		m:      bf.m,
+		k:      bf.k,
+		n:      n,
+		shift:  bf.shift,
+	}
+}
+
+func (bf *blockedBloomFilter) words() []uint64 {
+	return bf.bitmap
+}
+
+func (bf *blockedBloomFilter) compatible(f Bloom) bool {
+	o, ok := f.(*blockedBloomFilter)
+	return ok && o.blocks == bf.blocks && o.k == bf.k
+}
+
+func (bf *blockedBloomFilter) withWords(ws []uint64, n uint64) Bloom {
+	return &blockedBloomFilter{
+		bitmap: ws,
+		k:      bf.k,
+		n:      n,
+		blocks: bf.blocks,
+	}
+}
+
+func (bf *partitionedBloomFilter) words() []uint64 {
+	return bf.bitmap
+}
+
+func (bf *partitionedBloomFilter) compatible(f Bloom) bool {
+	o, ok := f.(*partitionedBloomFilter)
+	return ok && o.s == bf.s && o.k == bf.k
+}
+
+func (bf *partitionedBloomFilter) withWords(ws []uint64, n uint64) Bloom {
+	return &partitionedBloomFilter{
+		bitmap: ws,
+		k:      bf.k,
+		n:      n,
+		s:      bf.s,
+	}
+}
+
+func (bf *bloomFilter) counters() []uint16 {
+	return bf.bitmap
+}
+
+func (bf *bloomFilter) compatible(f Bloom) bool {
+	o, ok := f.(*bloomFilter)
+	return ok && o.m == bf.m && o.k == bf.k
+}
+
+func (bf *bloomFilter) withCounters(cs []uint16, n uint64) CountingBloom {
+	return &bloomFilter{
+		bitmap: cs,
+		m:      bf.m,
+		k:      bf.k,
+		n:      n,
+		shift:  bf.shift,
+	}
+}
+
+// counters returns a copy of the counters loaded atomically,
+// the counters are saturated at maxCounter so that they fit in uint16.
+func (bf *syncBloomFilter) counters() []uint16 {
+	cs := make([]uint16, len(bf.bitmap))
+	for i := range cs {
+		cs[i] = uint16(atomic.LoadUint32(&bf.bitmap[i]))
+	}
+	return cs
+}
+
+func (bf *syncBloomFilter) compatible(f Bloom) bool {
+	o, ok := f.(*syncBloomFilter)
+	return ok && o.m == bf.m && o.k == bf.k
+}
+
+func (bf *syncBloomFilter) withCounters(cs []uint16, n uint64) CountingBloom {
+	bm := make([]uint32, len(cs))
+	for i, c := range cs {
+		bm[i] = uint32(c)
+	}
+	return &syncBloomFilter{
+		bitmap: bm,
+		m:      bf.m,
+		k:      bf.k,
+		n:      n,
+		shift:  bf.shift,
+	}
+}
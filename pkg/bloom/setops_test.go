@@ -0,0 +1,200 @@
+package bloom_test
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/andy2046/gopie/pkg/bloom"
+)
+
+func addRange(f bloom.Bloom, from, to uint32) {
+	for i := from; i < to; i++ {
+		n := make([]byte, 4)
+		binary.BigEndian.PutUint32(n, i)
+		f.Add(n)
+	}
+}
+
+func existRange(f bloom.Bloom, from, to uint32) bool {
+	for i := from; i < to; i++ {
+		n := make([]byte, 4)
+		binary.BigEndian.PutUint32(n, i)
+		if !f.Exist(n) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestUnionIntersect(t *testing.T) {
+	for name, newFilter := range map[string]func() bloom.Bloom{
+		"counting":      func() bloom.Bloom { return bloom.NewGuess(2000, 0.01) },
+		"bit":           func() bloom.Bloom { return bloom.NewBGuess(2000, 0.01) },
+		"sync counting": func() bloom.Bloom { return bloom.NewSyncGuess(2000, 0.01) },
+		"sync bit":      func() bloom.Bloom { return bloom.NewSyncBGuess(2000, 0.01) },
+		"blocked":       func() bloom.Bloom { return bloom.NewBlockedGuess(2000, 0.01) },
+		"partitioned":   func() bloom.Bloom { return bloom.NewPartitionedGuess(2000, 0.01) },
+	} {
+		a, b := newFilter(), newFilter()
+		addRange(a, 0, 1000)
+		addRange(b, 500, 1500)
+
+		u, err := bloom.Union(a, b)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !existRange(u, 0, 1500) {
+			t.Errorf("%s: union should contain all the elements", name)
+		}
+		if n := u.N(); math.Abs(float64(n)-1500) > 75 {
+			t.Errorf("%s: union estimated N got %d want about 1500", name, n)
+		}
+
+		i, err := bloom.Intersect(a, b)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !existRange(i, 500, 1000) {
+			t.Errorf("%s: intersection should contain the common elements", name)
+		}
+		if n := i.N(); math.Abs(float64(n)-500) > 75 {
+			t.Errorf("%s: intersection estimated N got %d want about 500", name, n)
+		}
+		if a.N() != 1000 || b.N() != 1000 {
+			t.Errorf("%s: the filters should not be modified", name)
+		}
+	}
+}
+
+func TestUnionScalable(t *testing.T) {
+	for name, newFilter := range map[string]func(p float64) bloom.Bloom{
+		"scalable":      func(p float64) bloom.Bloom { return bloom.NewSGuess(100, p, 0.8) },
+		"sync scalable": func(p float64) bloom.Bloom { return bloom.NewSyncSGuess(100, p, 0.8) },
+	} {
+		a, b := newFilter(0.01), newFilter(0.01)
+		addRange(a, 0, 1000)
+		addRange(b, 1000, 1500)
+
+		u, err := bloom.Union(a, b)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !existRange(u, 0, 1500) {
+			t.Errorf("%s: union should contain all the elements", name)
+		}
+		if u.N() != 1500 {
+			t.Errorf("%s: union N got %d want 1500", name, u.N())
+		}
+
+		// the union does not share the bits with the filters.
+		addRange(a, 2000, 3000)
+		if existRange(u, 2000, 3000) {
+			t.Errorf("%s: union should not contain the elements added to the filters later", name)
+		}
+
+		if _, err := bloom.Intersect(a, b); err != bloom.ErrUnsupported {
+			t.Errorf("%s: got %v want %v", name, err, bloom.ErrUnsupported)
+		}
+		if _, err := bloom.Union(a, newFilter(0.001)); err != bloom.ErrIncompatible {
+			t.Errorf("%s: got %v want %v", name, err, bloom.ErrIncompatible)
+		}
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	for name, newFilter := range map[string]func() bloom.Bloom{
+		"stable": func() bloom.Bloom { return bloom.NewStableGuess(4096, 0.01) },
+		"aging":  func() bloom.Bloom { return bloom.NewAging(time.Minute, 1000, 0.01) },
+	} {
+		if _, err := bloom.Union(newFilter(), newFilter()); err != bloom.ErrUnsupported {
+			t.Errorf("%s: Union got %v want %v", name, err, bloom.ErrUnsupported)
+		}
+		if _, err := bloom.Intersect(newFilter(), newFilter()); err != bloom.ErrUnsupported {
+			t.Errorf("%s: Intersect got %v want %v", name, err, bloom.ErrUnsupported)
+		}
+	}
+}
+
+func TestUnionScalableMarshal(t *testing.T) {
+	fs := make([]bloom.Bloom, 70)
+	for i := range fs {
+		fs[i] = bloom.NewS(0.01)
+		addRange(fs[i], uint32(i*10), uint32(i*10+10))
+	}
+	u, err := bloom.Union(fs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the chain of the union is as long as all the chains together.
+	data, err := u.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u2, err := bloom.Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u2.N() != 700 || !existRange(u2, 0, 700) {
+		t.Error("unmarshalled union should contain all the elements")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	for name, newFilter := range map[string]func() bloom.CountingBloom{
+		"counting":      func() bloom.CountingBloom { return bloom.New(4096, 4) },
+		"sync counting": func() bloom.CountingBloom { return bloom.NewSync(4096, 4) },
+	} {
+		a, b := newFilter(), newFilter()
+		addRange(a, 0, 100)
+		addRange(b, 50, 150)
+
+		m, err := bloom.Merge(a, b)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if m.N() != 200 {
+			t.Errorf("%s: merge N got %d want 200", name, m.N())
+		}
+
+		// the common elements are removed once per filter.
+		n := make([]byte, 4)
+		binary.BigEndian.PutUint32(n, 60)
+		m.Remove(n)
+		if !m.Exist(n) {
+			t.Errorf("%s: element added twice should exist after removed once", name)
+		}
+		m.Remove(n)
+		if m.Exist(n) {
+			t.Errorf("%s: element added twice should not exist after removed twice", name)
+		}
+	}
+}
+
+func TestIncompatible(t *testing.T) {
+	for name, fs := range map[string][]bloom.Bloom{
+		"empty":   {},
+		"m":       {bloom.NewB(1024, 4), bloom.NewB(2048, 4)},
+		"k":       {bloom.NewB(1024, 4), bloom.NewB(1024, 3)},
+		"variant": {bloom.NewB(1024, 4), bloom.New(1024, 4)},
+		"sync":    {bloom.NewB(1024, 4), bloom.NewSyncB(1024, 4)},
+		"blocked": {bloom.NewBlocked(1024, 4), bloom.NewBlocked(2048, 4)},
+		"slices":  {bloom.NewPartitioned(4096, 4), bloom.NewPartitioned(4096, 8)},
+	} {
+		if _, err := bloom.Union(fs...); err != bloom.ErrIncompatible {
+			t.Errorf("%s: Union got %v want %v", name, err, bloom.ErrIncompatible)
+		}
+		if _, err := bloom.Intersect(fs...); err != bloom.ErrIncompatible {
+			t.Errorf("%s: Intersect got %v want %v", name, err, bloom.ErrIncompatible)
+		}
+	}
+
+	if _, err := bloom.Merge(bloom.New(1024, 4), bloom.NewSync(1024, 4)); err != bloom.ErrIncompatible {
+		t.Errorf("Merge got %v want %v", err, bloom.ErrIncompatible)
+	}
+	if _, err := bloom.Merge(bloom.New(1024, 4), bloom.New(1024, 3)); err != bloom.ErrIncompatible {
+		t.Errorf("Merge got %v want %v", err, bloom.ErrIncompatible)
+	}
+}
@@ -1,7 +1,16 @@
 // Package cached implements Caching Devil pattern.
 package cached
 
-import "time"
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
 
 type (
 	// Lease is a per-cache-key lock preventing thundering herds and stale sets.
@@ -44,90 +53,504 @@ type (
 		AtomicCheckAndSet(key string, expectedValue, valueToSet []byte) bool
 	}
 
+	// Deleter is optionally implemented by the Cache which can delete keys, e.g. MemoryCache and Memcache.
+	// Invalidate requires the Cache to be a Deleter,
+	// the leases are otherwise released when they expire rather than deleted.
+	Deleter interface {
+		// Delete removes the provided key from the cache layer, deleting a missing key is not an error.
+		Delete(key string) error
+	}
+
+	// KeyChecker is optionally implemented by the Cache which restricts the keys, e.g. Memcache,
+	// Cacher returns the error of CheckKey for the key instead of reading it,
+	// as the Cache can not report the error from Get, AtomicAdd and AtomicCheckAndSet.
+	KeyChecker interface {
+		// CheckKey returns an error if key can not be stored in the Cache.
+		CheckKey(key string) error
+	}
+
+	// TTLSetter is optionally implemented by the Cache which can set a value expiring after ttl,
+	// Cacher only stashes the value behind a lease if the Cache is a TTLSetter,
+	// so that the stashed value expires with the lease.
+	TTLSetter interface {
+		// SetTTL is like Set but the value expires after ttl.
+		SetTTL(key string, value []byte, ttl time.Duration) error
+	}
+
+	// leaseExpirer is implemented by the Lessor whose leases expire, e.g. HMACLessor,
+	// Cacher takes over the expired lease so that a failed lease holder doesn't block the key forever.
+	leaseExpirer interface {
+		Expiry(value []byte) (time.Time, bool)
+	}
+
 	// TruthTeller is the function to fetch the value associated with the looked up key
 	// from the source of truth data store.
 	TruthTeller func(key string) []byte
 
+	// TruthTellerContext is like TruthTeller but honours ctx and may fail.
+	TruthTellerContext func(ctx context.Context, key string) ([]byte, error)
+
+	// WaitPolicy decides what Cacher does after waiting for a lease MaxLeaseWaits times.
+	WaitPolicy int
+
 	// Cacher manages Caching Devil.
 	Cacher struct {
-		interval time.Duration
-		cache    Cache
-		lessor   Lessor
-		teller   TruthTeller
+		interval      time.Duration
+		maxLeaseWaits int
+		waitPolicy    WaitPolicy
+		softTTL       time.Duration
+		hardTTL       time.Duration
+		cache         Cache
+		lessor        Lessor
+		teller        TruthTellerContext
+	}
+
+	// Config is the config for Cacher.
+	Config struct {
+		// Interval is the retry waiting time if another request is holding the lease.
+		Interval time.Duration
+		// MaxLeaseWaits is the maximum number of times to wait for the lease on a key,
+		// including the times the cache fails to add the lease,
+		// zero means waiting until the lease is released.
+		MaxLeaseWaits int
+		// WaitPolicy is applied after waiting MaxLeaseWaits times.
+		WaitPolicy WaitPolicy
+		// SoftTTL is the time after which the value populated by Cacher is stale,
+		// one reader refreshes the stale value in the background
+		// while the others keep getting the stale value until HardTTL.
+		// Zero means the value is never stale unless HardTTL is set,
+		// HardTTL must be set if SoftTTL is set.
+		SoftTTL time.Duration
+		// HardTTL is the time after which the value populated by Cacher is expired,
+		// zero means the value never expires.
+		HardTTL time.Duration
+	}
+
+	// Option applies config to Config.
+	Option = func(*Config) error
+)
+
+const (
+	// WaitError returns ErrLeaseWait.
+	WaitError WaitPolicy = iota
+	// WaitTruth fetches the value from the source of truth without populating the cache.
+	WaitTruth
+	// WaitStale returns the stale value saved by Invalidate if any, ErrLeaseWait otherwise.
+	WaitStale
+)
+
+const (
+	// reservedPrefix starts the keys used by Cacher internally,
+	// 0xff never appears in UTF-8 so that it doesn't collide with the keys of the values.
+	reservedPrefix = "\xffcached:"
+	// stalePrefix is prepended to the key to save the stale value.
+	stalePrefix = reservedPrefix + "stale:"
+	// refreshPrefix is prepended to the key to hold the lease for refreshing the stale value.
+	refreshPrefix = reservedPrefix + "refresh:"
+	// ttlMagic prefixes the value populated with SoftTTL or HardTTL,
+	// followed by the soft and hard expiration time in UnixNano.
+	ttlMagic  = "\x00ttl"
+	ttlHeader = len(ttlMagic) + 16
+)
+
+var (
+	// ErrLeaseWait when another request is still holding the lease after MaxLeaseWaits.
+	ErrLeaseWait = errors.New("too many waits for the lease")
+	// ErrReservedKey when the key starts with the prefix reserved by Cacher, which is not valid UTF-8.
+	ErrReservedKey = errors.New("key is reserved by Cacher")
+	// ErrNotDeleter when Invalidate is called with a Cache which is not a Deleter.
+	ErrNotDeleter = errors.New("cache can not delete keys")
+	// ErrCacheUnavailable when the Cache does not return a value for each key.
+	ErrCacheUnavailable = errors.New("cache is unavailable")
+	// DefaultConfig is the default config for Cacher.
+	DefaultConfig = Config{
+		Interval:      10 * time.Millisecond,
+		MaxLeaseWaits: 0,
+		WaitPolicy:    WaitError,
 	}
 )
 
 // New returns a new Cacher.
 // interval is the retry waiting time if another request is holding the lease.
 func New(interval time.Duration, cache Cache, lessor Lessor, teller TruthTeller) Cacher {
+	return NewContext(cache, lessor, func(_ context.Context, key string) ([]byte, error) {
+		return teller(key), nil
+	}, func(c *Config) error {
+		c.Interval = interval
+		return nil
+	})
+}
+
+// NewContext returns a new Cacher using teller which honours context and may fail.
+// It panics if lessor is an HMACLessor with random secret and cache is not a MemoryCache,
+// as the leases of the other processes sharing the cache can not be verified.
+func NewContext(cache Cache, lessor Lessor, teller TruthTellerContext, options ...Option) Cacher {
+	c := DefaultConfig
+	for _, option := range options {
+		if err := option(&c); err != nil {
+			log.Panicf("fail to apply Config -> %v\n", err)
+		}
+	}
+	if c.Interval < 0 || c.MaxLeaseWaits < 0 || c.SoftTTL < 0 || c.HardTTL < 0 ||
+		(c.SoftTTL > 0 && c.HardTTL == 0) || c.SoftTTL > c.HardTTL {
+		log.Panicf("invalid Config -> %+v\n", c)
+	}
+	if l, ok := lessor.(*HMACLessor); ok && l.random {
+		if _, ok := cache.(*MemoryCache); !ok {
+			log.Panicln("HMACLessor with random secret can not be used with a shared Cache")
+		}
+	}
 	return Cacher{
-		interval: interval,
-		cache:    cache,
-		lessor:   lessor,
-		teller:   teller,
+		interval:      c.Interval,
+		maxLeaseWaits: c.MaxLeaseWaits,
+		waitPolicy:    c.WaitPolicy,
+		softTTL:       c.SoftTTL,
+		hardTTL:       c.HardTTL,
+		cache:         cache,
+		lessor:        lessor,
+		teller:        teller,
 	}
 }
 
 // Read try to retrieve the value associated with the provided key.
 func (c Cacher) Read(key string) []byte {
-	return c.read(key, []Lease{})
+	value, _ := c.ReadContext(context.Background(), key)
+	return value
 }
 
-func (c Cacher) read(key string, previouslySeenLeases []Lease) []byte {
-	cacheKeysToLookUp := []string{key}
-	for _, lease := range previouslySeenLeases {
-		cacheKeysToLookUp = append(cacheKeysToLookUp, lease.Nonce())
+// ReadContext try to retrieve the value associated with the provided key,
+// it returns the error from ctx, the TruthTellerContext, ErrLeaseWait, ErrReservedKey
+// or the error from KeyChecker.
+func (c Cacher) ReadContext(ctx context.Context, key string) ([]byte, error) {
+	if err := c.checkKey(key); err != nil {
+		return nil, err
+	}
+	return c.read(ctx, key, nil, false)
+}
+
+// ReadMany is like ReadContext for multiple keys,
+// the keys are looked up by a single cache Get and the missing ones are read concurrently.
+// The value associated with a certain key will be in the same position in the returned list
+// as the key is in the keys list, the first error encountered is returned.
+func (c Cacher) ReadMany(ctx context.Context, keys ...string) ([][]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if err := c.checkKey(key); err != nil {
+			return nil, err
+		}
+	}
+	values := c.cache.Get(keys...)
+	if len(values) != len(keys) {
+		return nil, ErrCacheUnavailable
 	}
 
-	// looking up the provided key as well as all previously seen leases
 	var (
-		valueForKey     []byte
-		valuesFromCache = c.cache.Get(cacheKeysToLookUp...)
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
 	)
+	for i, key := range keys {
+		if values[i] != nil && !c.lessor.IsLease(values[i]) {
+			if value, ok := c.fromCache(key, values[i]); ok {
+				values[i] = value
+				continue
+			}
+		}
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			value, err := c.read(ctx, key, values[i], true)
+			if err != nil {
+				errOnce.Do(func() { firstErr = err })
+				return
+			}
+			values[i] = value
+		}(i, key)
+	}
+	wg.Wait()
 
-	if len(valuesFromCache) == 0 {
-		return nil
+	if firstErr != nil {
+		return nil, firstErr
 	}
-	valueForKey, valuesFromCache = valuesFromCache[0], valuesFromCache[1:]
+	return values, nil
+}
 
-	// check if the value is stashed behind one of the leases we've previously seen
-	// avoid reader to experience a ridiculous amount of latency as it waits for other readers to populate the cache key
-	for _, valueForPreviouslySeenLease := range valuesFromCache {
-		if valueForPreviouslySeenLease != nil {
-			return valueForPreviouslySeenLease
+// Invalidate deletes the value associated with the provided key from the cache,
+// it should be called after the source of truth is updated.
+// Any outstanding lease on the key is deleted as well,
+// so the lease holder can not set the value it read from the truth before the update.
+// If WaitPolicy is WaitStale, the deleted value is saved as the stale value
+// until the value is populated again.
+// It returns ErrNotDeleter if the Cache is not a Deleter.
+func (c Cacher) Invalidate(key string) error {
+	if err := c.checkKey(key); err != nil {
+		return err
+	}
+	d, ok := c.cache.(Deleter)
+	if !ok {
+		return ErrNotDeleter
+	}
+	if c.waitPolicy == WaitStale {
+		if values := c.cache.Get(key); len(values) > 0 && values[0] != nil && !c.lessor.IsLease(values[0]) {
+			// saving the stale value is best effort.
+			_ = c.cache.Set(stalePrefix+key, values[0])
 		}
 	}
+	return d.Delete(key)
+}
 
-	if valueForKey == nil {
-		// value is not in the cache
-		newLease := c.lessor.NewLease()
-		nonceBytes := []byte(newLease.Nonce())
-		leaseAdded := c.cache.AtomicAdd(key, nonceBytes)
+// read looks up the provided key until it gets the value,
+// if fetched is true, valueForKey is already looked up from the cache.
+func (c Cacher) read(ctx context.Context, key string, valueForKey []byte, fetched bool) ([]byte, error) {
+	var (
+		previouslySeenLeases []Lease
+		waits                int
+	)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 
-		if leaseAdded {
-			// managed to acquire a lease on this key,
-			// now populate the cache with the value from data store
-			valueFromTruth := c.teller(key)
+		if !fetched {
+			cacheKeysToLookUp := []string{key}
+			for _, lease := range previouslySeenLeases {
+				cacheKeysToLookUp = append(cacheKeysToLookUp, lease.Nonce())
+			}
 
-			// avoid cache poisoning with stale value
-			// if CAS returns false, the value is invalidated by write
-			_ = c.cache.AtomicCheckAndSet(key, nonceBytes, valueFromTruth)
+			// looking up the provided key as well as all previously seen leases
+			valuesFromCache := c.cache.Get(cacheKeysToLookUp...)
+			if len(valuesFromCache) != len(cacheKeysToLookUp) {
+				return nil, ErrCacheUnavailable
+			}
+			valueForKey, valuesFromCache = valuesFromCache[0], valuesFromCache[1:]
 
+			// check if the value is stashed behind one of the leases we've previously seen
 			// avoid reader to experience a ridiculous amount of latency as it waits for other readers to populate the cache key
-			c.cache.Set(newLease.Nonce(), valueFromTruth)
-			return valueFromTruth
+			for _, valueForPreviouslySeenLease := range valuesFromCache {
+				if valueForPreviouslySeenLease != nil {
+					return valueForPreviouslySeenLease, nil
+				}
+			}
+		}
+		fetched = false
+
+		isLease := valueForKey != nil && c.lessor.IsLease(valueForKey)
+		if valueForKey != nil && !isLease {
+			if value, ok := c.fromCache(key, valueForKey); ok {
+				// got the value from cache, return it
+				return value, nil
+			}
+		}
+
+		if !isLease || c.leaseExpired(valueForKey) {
+			// value is not in the cache or expired, or the lease holder failed to release the lease
+			newLease := c.lessor.NewLease()
+			nonceBytes := []byte(newLease.Nonce())
+			var leaseAdded bool
+			if valueForKey == nil {
+				leaseAdded = c.cache.AtomicAdd(key, nonceBytes)
+			} else {
+				leaseAdded = c.cache.AtomicCheckAndSet(key, valueForKey, nonceBytes)
+			}
+
+			if leaseAdded {
+				// managed to acquire a lease on this key,
+				// now populate the cache with the value from data store
+				valueFromTruth, err := c.teller(ctx, key)
+				if err != nil {
+					// release the lease so that other requests don't wait for it,
+					// deleting a value set by write in the meantime is harmless.
+					c.delete(key)
+					return nil, err
+				}
+
+				// avoid cache poisoning with stale value
+				// if CAS returns false, the value is invalidated by write or Invalidate
+				if c.cache.AtomicCheckAndSet(key, nonceBytes, c.wrap(valueFromTruth)) && c.waitPolicy == WaitStale {
+					// the stale value is no longer needed once the value is populated.
+					c.delete(stalePrefix + key)
+				}
+
+				// avoid reader to experience a ridiculous amount of latency as it waits for other readers to populate the cache key
+				c.stash(nonceBytes, valueFromTruth)
+				return valueFromTruth, nil
+			}
+
+			// another request managed to acquire the lease before me or the cache failed to add it,
+			// wait as for a lease so that a failing cache doesn't make it spin.
+			isLease = false
 		}
 
-		// another request managed to acquire the lease before me, retry
-		return c.read(key, previouslySeenLeases)
-	} else if c.lessor.IsLease(valueForKey) {
 		// another request is holding the lease on this key, try again later
-		time.Sleep(c.interval)
+		if c.maxLeaseWaits > 0 && waits >= c.maxLeaseWaits {
+			return c.onLeaseWait(ctx, key)
+		}
+		waits++
+		if err := sleep(ctx, c.interval); err != nil {
+			return nil, err
+		}
+		if !isLease {
+			continue
+		}
+		// the lease signed with another secret is not looked up.
+		if lease, err := c.lessor.FromValue(valueForKey); err == nil {
+			previouslySeenLeases = append(previouslySeenLeases, lease)
+		}
+	}
+}
+
+// delete removes key if the Cache is a Deleter, deleting is best effort.
+func (c Cacher) delete(key string) {
+	if d, ok := c.cache.(Deleter); ok {
+		_ = d.Delete(key)
+	}
+}
+
+// stash saves value behind the lease nonce for the readers waiting for the lease,
+// the value expires with the lease so that the stashed values don't pile up.
+func (c Cacher) stash(nonce, value []byte) {
+	ts, ok := c.cache.(TTLSetter)
+	if !ok {
+		return
+	}
+	l, ok := c.lessor.(leaseExpirer)
+	if !ok {
+		return
+	}
+	if expiry, ok := l.Expiry(nonce); ok {
+		if ttl := time.Until(expiry); ttl > 0 {
+			_ = ts.SetTTL(string(nonce), value, ttl)
+		}
+	}
+}
+
+// leaseExpired returns true if the lease value has expired.
+func (c Cacher) leaseExpired(value []byte) bool {
+	l, ok := c.lessor.(leaseExpirer)
+	if !ok {
+		return false
+	}
+	expiry, ok := l.Expiry(value)
+	return ok && !time.Now().Before(expiry)
+}
 
-		previouslySeenLeases = append(previouslySeenLeases, c.lessor.MustFromValue(valueForKey))
-		return c.read(key, previouslySeenLeases)
-	} else {
-		// got the value from cache, return it
-		return valueForKey
+// onLeaseWait applies WaitPolicy after waiting MaxLeaseWaits times.
+func (c Cacher) onLeaseWait(ctx context.Context, key string) ([]byte, error) {
+	switch c.waitPolicy {
+	case WaitTruth:
+		return c.teller(ctx, key)
+	case WaitStale:
+		if values := c.cache.Get(stalePrefix + key); len(values) > 0 && values[0] != nil {
+			value, _, _ := c.unwrap(values[0])
+			return value, nil
+		}
+	}
+	return nil, ErrLeaseWait
+}
+
+// fromCache returns the value unwrapped from raw if it is not expired,
+// the stale value is refreshed in the background.
+func (c Cacher) fromCache(key string, raw []byte) ([]byte, bool) {
+	value, soft, hard := c.unwrap(raw)
+	now := time.Now().UnixNano()
+	if hard != 0 && now >= hard {
+		return nil, false
+	}
+	if soft != 0 && now >= soft {
+		c.revalidate(key, raw)
+	}
+	return value, true
+}
+
+// revalidate refreshes the stale value raw in the background
+// if it manages to acquire the refresh lease on key,
+// the expired refresh lease is taken over in case the refreshing request failed to release it.
+func (c Cacher) revalidate(key string, raw []byte) {
+	refreshKey := refreshPrefix + key
+	nonce := []byte(c.lessor.NewLease().Nonce())
+	if !c.cache.AtomicAdd(refreshKey, nonce) {
+		values := c.cache.Get(refreshKey)
+		if len(values) == 0 || values[0] == nil || !c.leaseExpired(values[0]) ||
+			!c.cache.AtomicCheckAndSet(refreshKey, values[0], nonce) {
+			// another request is refreshing the value
+			return
+		}
+	}
+
+	go func() {
+		defer c.delete(refreshKey)
+
+		valueFromTruth, err := c.teller(context.Background(), key)
+		if err != nil {
+			return
+		}
+		// if CAS returns false, the value is invalidated by write or Invalidate
+		_ = c.cache.AtomicCheckAndSet(key, raw, c.wrap(valueFromTruth))
+	}()
+}
+
+// wrap prefixes value with the soft and hard expiration time if any.
+func (c Cacher) wrap(value []byte) []byte {
+	if c.softTTL == 0 && c.hardTTL == 0 {
+		return value
+	}
+
+	var soft, hard int64
+	now := time.Now()
+	if c.hardTTL > 0 {
+		hard = now.Add(c.hardTTL).UnixNano()
+		soft = hard
+	}
+	if c.softTTL > 0 {
+		soft = now.Add(c.softTTL).UnixNano()
+	}
+
+	raw := make([]byte, ttlHeader, ttlHeader+len(value))
+	copy(raw, ttlMagic)
+	binary.BigEndian.PutUint64(raw[len(ttlMagic):], uint64(soft))
+	binary.BigEndian.PutUint64(raw[len(ttlMagic)+8:], uint64(hard))
+	return append(raw, value...)
+}
+
+// unwrap returns the value and the soft and hard expiration time of raw,
+// the expiration time is zero if raw is not wrapped.
+// raw is only unwrapped if the Cacher wraps the values,
+// so that a value which happens to start with ttlMagic is kept intact otherwise.
+func (c Cacher) unwrap(raw []byte) (value []byte, soft, hard int64) {
+	if (c.softTTL == 0 && c.hardTTL == 0) ||
+		len(raw) < ttlHeader || !bytes.HasPrefix(raw, []byte(ttlMagic)) {
+		return raw, 0, 0
+	}
+	soft = int64(binary.BigEndian.Uint64(raw[len(ttlMagic):]))
+	hard = int64(binary.BigEndian.Uint64(raw[len(ttlMagic)+8:]))
+	return raw[ttlHeader:], soft, hard
+}
+
+// checkKey returns ErrReservedKey if key starts with reservedPrefix,
+// or the error of KeyChecker for key and the longest key derived from it.
+func (c Cacher) checkKey(key string) error {
+	if strings.HasPrefix(key, reservedPrefix) {
+		return ErrReservedKey
+	}
+	if kc, ok := c.cache.(KeyChecker); ok {
+		if err := kc.CheckKey(key); err != nil {
+			return err
+		}
+		return kc.CheckKey(refreshPrefix + key)
+	}
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
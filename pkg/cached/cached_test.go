@@ -2,11 +2,13 @@ package cached_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"math/rand"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -177,6 +179,214 @@ func TestThunderingHerdProtection(t *testing.T) {
 	}
 }
 
+func TestInvalidation(t *testing.T) {
+	reset()
+	k := "key3"
+	expected := []byte(k + "-from-truth")
+	c := newCache()
+	l := newLeaseLessor()
+	cd := cached.New(1*time.Millisecond, c, l, truthTeller)
+
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+
+	go func() {
+		<-afterFromTruth // reader already retrive value from truth
+		if err := cd.Invalidate(k); err != nil {
+			t.Errorf("invalidate error %v", err)
+		}
+		beforeCAS <- struct{}{}
+
+		t.Log("invalidate ok")
+		wg.Done()
+	}()
+
+	go func() {
+		value := cd.Read(k)
+		if !bytes.Equal(value, expected) {
+			t.Errorf("read expected %s got %s", expected, value)
+		}
+
+		t.Log("read ok")
+		wg.Done()
+	}()
+
+	wg.Wait()
+
+	// the value read before Invalidate must not be set by the lease holder
+	if values := c.Get(k); values[0] != nil {
+		t.Fatalf("invalidate expected nil got %s", values[0])
+	}
+
+	if i := get(); i != 1 {
+		t.Fatalf("counter expected %d got %d", 1, i)
+	}
+}
+
+func TestReadContextCancel(t *testing.T) {
+	c, l := cached.NewMemoryCache(1, 0), cached.NewHMACLessor(nil, time.Minute)
+	cd := cached.New(1*time.Millisecond, c, l, truthTeller)
+	// another request is holding the lease.
+	c.AtomicAdd("key", []byte(l.NewLease().Nonce()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := cd.ReadContext(ctx, "key"); err != context.DeadlineExceeded {
+		t.Fatalf("read expected %v got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestWaitPolicy(t *testing.T) {
+	teller := func(_ context.Context, key string) ([]byte, error) {
+		return []byte(key + "-from-truth"), nil
+	}
+
+	for _, tc := range []struct {
+		policy   cached.WaitPolicy
+		expected []byte
+		err      error
+	}{
+		{cached.WaitError, nil, cached.ErrLeaseWait},
+		{cached.WaitTruth, []byte("key-from-truth"), nil},
+		{cached.WaitStale, []byte("key-stale"), nil},
+	} {
+		c, l := cached.NewMemoryCache(1, 0), cached.NewHMACLessor(nil, time.Minute)
+		cd := cached.NewContext(c, l, teller, func(c *cached.Config) error {
+			c.Interval = time.Millisecond
+			c.MaxLeaseWaits = 2
+			c.WaitPolicy = tc.policy
+			return nil
+		})
+		c.Set("key", []byte("key-stale"))
+		if err := cd.Invalidate("key"); err != nil {
+			t.Fatal(err)
+		}
+		lease := []byte(l.NewLease().Nonce())
+		c.AtomicAdd("key", lease)
+
+		value, err := cd.ReadContext(context.Background(), "key")
+		if err != tc.err || !bytes.Equal(value, tc.expected) {
+			t.Errorf("policy %d expected %s %v got %s %v", tc.policy, tc.expected, tc.err, value, err)
+		}
+		if values := c.Get("key"); !bytes.Equal(values[0], lease) {
+			t.Errorf("policy %d should not populate the cache, got %s", tc.policy, values[0])
+		}
+	}
+}
+
+func TestTruthTellerError(t *testing.T) {
+	errTruth := errors.New("truth error")
+	c, l := cached.NewMemoryCache(1, 0), cached.NewHMACLessor(nil, time.Minute)
+	cd := cached.NewContext(c, l, func(_ context.Context, key string) ([]byte, error) {
+		return nil, errTruth
+	})
+
+	if _, err := cd.ReadContext(context.Background(), "key"); err != errTruth {
+		t.Fatalf("read expected %v got %v", errTruth, err)
+	}
+	if values := c.Get("key"); values[0] != nil {
+		t.Fatalf("lease expected to be released got %s", values[0])
+	}
+}
+
+func TestReadMany(t *testing.T) {
+	var calls int32
+	c, l := cached.NewMemoryCache(4, 0), cached.NewHMACLessor(nil, time.Minute)
+	cd := cached.NewContext(c, l, func(_ context.Context, key string) ([]byte, error) {
+		atomic.AddInt32(&calls, 1)
+		return []byte(key + "-from-truth"), nil
+	})
+	c.Set("k2", []byte("k2-from-cache"))
+
+	values, err := cd.ReadMany(context.Background(), "k1", "k2", "k3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"k1-from-truth", "k2-from-cache", "k3-from-truth"}
+	for i := range expected {
+		if string(values[i]) != expected[i] {
+			t.Errorf("read many expected %s got %s", expected[i], values[i])
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("counter expected %d got %d", 2, n)
+	}
+
+	// the Cache returning fewer values than keys is unavailable.
+	cd = cached.NewContext(shortCache{newCache()}, newLeaseLessor(), func(_ context.Context, key string) ([]byte, error) {
+		return []byte(key + "-from-truth"), nil
+	})
+	if _, err := cd.ReadMany(context.Background(), "k1", "k2"); err != cached.ErrCacheUnavailable {
+		t.Fatalf("read many expected %v got %v", cached.ErrCacheUnavailable, err)
+	}
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	var calls int32
+	c, l := cached.NewMemoryCache(1, 0), cached.NewHMACLessor(nil, time.Minute)
+	cd := cached.NewContext(c, l, func(_ context.Context, key string) ([]byte, error) {
+		n := atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		return []byte(fmt.Sprintf("%s-v%d", key, n)), nil
+	}, func(c *cached.Config) error {
+		c.SoftTTL = 20 * time.Millisecond
+		c.HardTTL = time.Minute
+		return nil
+	})
+
+	if value := cd.Read("key"); string(value) != "key-v1" {
+		t.Fatalf("read expected %s got %s", "key-v1", value)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	// the stale value is served while one reader refreshes it in the background
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if value := cd.Read("key"); string(value) != "key-v1" {
+				t.Errorf("read expected %s got %s", "key-v1", value)
+			}
+		}()
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(time.Second)
+	for string(cd.Read("key")) != "key-v2" {
+		if time.Now().After(deadline) {
+			t.Fatal("stale value is not refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("counter expected %d got %d", 2, n)
+	}
+}
+
+func TestHardTTL(t *testing.T) {
+	var calls int32
+	c, l := cached.NewMemoryCache(1, 0), cached.NewHMACLessor(nil, time.Minute)
+	cd := cached.NewContext(c, l, func(_ context.Context, key string) ([]byte, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return []byte(fmt.Sprintf("%s-v%d", key, n)), nil
+	}, func(c *cached.Config) error {
+		c.HardTTL = 20 * time.Millisecond
+		return nil
+	})
+
+	if value := cd.Read("key"); string(value) != "key-v1" {
+		t.Fatalf("read expected %s got %s", "key-v1", value)
+	}
+	if value := cd.Read("key"); string(value) != "key-v1" {
+		t.Fatalf("read expected %s got %s", "key-v1", value)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if value := cd.Read("key"); string(value) != "key-v2" {
+		t.Fatalf("read expected %s got %s", "key-v2", value)
+	}
+}
+
 func (fl *fakeLease) Nonce() string {
 	return fl.nonce
 }
@@ -253,6 +463,186 @@ func (fc *fakeCache) AtomicCheckAndSet(key string, expectedValue, valueToSet []b
 	return false
 }
 
+func (fc *fakeCache) Delete(key string) error {
+	fc.Lock()
+	defer fc.Unlock()
+
+	delete(fc.store, key)
+	return nil
+}
+
+func TestStaleRefreshLeaseExpiry(t *testing.T) {
+	c, l := cached.NewMemoryCache(1, 0), cached.NewHMACLessor(nil, 10*time.Millisecond)
+	cd := cached.NewContext(c, l, func(_ context.Context, key string) ([]byte, error) {
+		return []byte(key + "-from-truth"), nil
+	}, func(c *cached.Config) error {
+		c.SoftTTL = time.Millisecond
+		c.HardTTL = time.Minute
+		return nil
+	})
+
+	// the refreshing request failed without releasing the refresh lease.
+	c.Set("\xffcached:refresh:key", []byte(l.NewLease().Nonce()))
+	if value := cd.Read("key"); string(value) != "key-from-truth" {
+		t.Fatalf("read expected %s got %s", "key-from-truth", value)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	cd.Read("key")
+	deadline := time.Now().Add(time.Second)
+	for len(c.Get("\xffcached:refresh:key")[0]) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expired refresh lease is not taken over")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestUnwrappedValue(t *testing.T) {
+	c, l := cached.NewMemoryCache(1, 0), cached.NewHMACLessor(nil, time.Minute)
+	cd := cached.NewContext(c, l, func(_ context.Context, key string) ([]byte, error) {
+		return []byte(key + "-from-truth"), nil
+	})
+
+	// the value looking like a wrapped one is intact without SoftTTL and HardTTL.
+	value := []byte("\x00ttl0123456789abcdef-value")
+	c.Set("key", value)
+	if got := cd.Read("key"); string(got) != string(value) {
+		t.Fatalf("read expected %q got %q", value, got)
+	}
+}
+
+func TestReservedKey(t *testing.T) {
+	c, l := cached.NewMemoryCache(1, 0), cached.NewHMACLessor(nil, time.Minute)
+	cd := cached.NewContext(c, l, func(_ context.Context, key string) ([]byte, error) {
+		return []byte(key + "-from-truth"), nil
+	})
+
+	key := "\xffcached:refresh:key"
+	if _, err := cd.ReadContext(context.Background(), key); err != cached.ErrReservedKey {
+		t.Errorf("read expected %v got %v", cached.ErrReservedKey, err)
+	}
+	if _, err := cd.ReadMany(context.Background(), "key", key); err != cached.ErrReservedKey {
+		t.Errorf("read many expected %v got %v", cached.ErrReservedKey, err)
+	}
+	if err := cd.Invalidate(key); err != cached.ErrReservedKey {
+		t.Errorf("invalidate expected %v got %v", cached.ErrReservedKey, err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("SoftTTL without HardTTL should panic")
+		}
+	}()
+	cached.NewContext(c, l, nil, func(c *cached.Config) error {
+		c.SoftTTL = time.Second
+		return nil
+	})
+}
+
+func TestLeaseAddFailure(t *testing.T) {
+	c := &rejectingCache{MemoryCache: cached.NewMemoryCache(1, 0)}
+	cd := cached.NewContext(c, cached.NewHMACLessor([]byte("secret"), time.Minute), func(_ context.Context, key string) ([]byte, error) {
+		return []byte(key + "-from-truth"), nil
+	}, func(c *cached.Config) error {
+		c.Interval = time.Millisecond
+		c.MaxLeaseWaits = 3
+		return nil
+	})
+
+	// the cache refuses to add the lease on the illegal key, which is not a lease race.
+	if _, err := cd.ReadContext(context.Background(), "bad key"); err != cached.ErrLeaseWait {
+		t.Fatalf("read expected %v got %v", cached.ErrLeaseWait, err)
+	}
+	if n := atomic.LoadInt32(&c.adds); n != 4 {
+		t.Fatalf("lease add expected %d got %d", 4, n)
+	}
+}
+
+// rejectingCache refuses to store the keys containing space like memcached.
+type rejectingCache struct {
+	*cached.MemoryCache
+	adds int32
+}
+
+func (rc *rejectingCache) AtomicAdd(key string, value []byte) bool {
+	atomic.AddInt32(&rc.adds, 1)
+	return !strings.Contains(key, " ") && rc.MemoryCache.AtomicAdd(key, value)
+}
+
+func TestStaleDeleted(t *testing.T) {
+	c, l := cached.NewMemoryCache(1, 0), cached.NewHMACLessor(nil, time.Minute)
+	cd := cached.NewContext(c, l, func(_ context.Context, key string) ([]byte, error) {
+		return []byte(key + "-from-truth"), nil
+	}, func(c *cached.Config) error {
+		c.WaitPolicy = cached.WaitStale
+		return nil
+	})
+
+	c.Set("key", []byte("key-stale"))
+	if err := cd.Invalidate("key"); err != nil {
+		t.Fatal(err)
+	}
+	if values := c.Get("\xffcached:stale:key"); string(values[0]) != "key-stale" {
+		t.Fatalf("stale value expected %s got %s", "key-stale", values[0])
+	}
+	// the stale value is deleted once the value is populated.
+	if value := cd.Read("key"); string(value) != "key-from-truth" {
+		t.Fatalf("read expected %s got %s", "key-from-truth", value)
+	}
+	if values := c.Get("\xffcached:stale:key"); values[0] != nil {
+		t.Fatalf("stale value should be deleted, got %s", values[0])
+	}
+}
+
+func TestNotDeleter(t *testing.T) {
+	c := noDeleteCache{cached.NewMemoryCache(1, 0)}
+	cd := cached.NewContext(c, cached.NewHMACLessor([]byte("secret"), time.Minute), func(_ context.Context, key string) ([]byte, error) {
+		return []byte(key + "-from-truth"), nil
+	})
+
+	if value := cd.Read("key"); string(value) != "key-from-truth" {
+		t.Fatalf("read expected %s got %s", "key-from-truth", value)
+	}
+	if err := cd.Invalidate("key"); err != cached.ErrNotDeleter {
+		t.Fatalf("invalidate expected %v got %v", cached.ErrNotDeleter, err)
+	}
+}
+
+// noDeleteCache is a Cache which is not a Deleter.
+type noDeleteCache struct {
+	fc *cached.MemoryCache
+}
+
+func (nc noDeleteCache) Get(keys ...string) [][]byte {
+	return nc.fc.Get(keys...)
+}
+
+func (nc noDeleteCache) Set(key string, value []byte) error {
+	return nc.fc.Set(key, value)
+}
+
+func (nc noDeleteCache) AtomicAdd(key string, value []byte) bool {
+	return nc.fc.AtomicAdd(key, value)
+}
+
+func (nc noDeleteCache) AtomicCheckAndSet(key string, expectedValue, valueToSet []byte) bool {
+	return nc.fc.AtomicCheckAndSet(key, expectedValue, valueToSet)
+}
+
+// shortCache drops the value of the last key.
+type shortCache struct {
+	*fakeCache
+}
+
+func (sc shortCache) Get(keys ...string) [][]byte {
+	values := sc.fakeCache.Get(keys...)
+	if len(values) == 0 {
+		return values
+	}
+	return values[:len(values)-1]
+}
+
 func randomString(l int) string {
 	bytes := make([]byte, l)
 	for i := 0; i < l; i++ {
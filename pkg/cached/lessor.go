@@ -0,0 +1,141 @@
+package cached
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+type (
+	// HMACLessor is a Lessor generating unforgeable leases signed by HMAC-SHA256,
+	// each lease expires after ttl so that a crashed lease holder doesn't block the key forever.
+	HMACLessor struct {
+		secret []byte
+		ttl    time.Duration
+		random bool // secret is random, the Lessor can not be used with a shared Cache
+	}
+
+	hmacLease struct {
+		nonce  string
+		expiry time.Time
+	}
+)
+
+const (
+	leasePrefix = "LEASE:"
+	// nonce is leasePrefix + hex(expiry 8 bytes + random 16 bytes + mac 32 bytes).
+	leaseRandomLen = 16
+	leasePayload   = 8 + leaseRandomLen
+	leaseLen       = len(leasePrefix) + 2*(leasePayload+sha256.Size)
+)
+
+var (
+	// ErrNotLease is returned when the value is not a valid lease.
+	ErrNotLease = errors.New("not a lease")
+)
+
+// NewHMACLessor returns a new HMACLessor, leases expire after ttl.
+// secret must be the same for all the Lessors using a Cache shared by processes, e.g. Memcache.
+// If secret is empty, a random secret is used,
+// which is only valid for a Cache local to the process, e.g. MemoryCache.
+func NewHMACLessor(secret []byte, ttl time.Duration) *HMACLessor {
+	if ttl <= 0 {
+		panic("ttl must be positive")
+	}
+	random := len(secret) == 0
+	if random {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(err)
+		}
+	}
+	return &HMACLessor{
+		secret: append([]byte(nil), secret...),
+		ttl:    ttl,
+		random: random,
+	}
+}
+
+// NewLease create a new Lease.
+func (l *HMACLessor) NewLease() Lease {
+	payload := make([]byte, leasePayload)
+	expiry := time.Now().Add(l.ttl)
+	binary.BigEndian.PutUint64(payload, uint64(expiry.UnixNano()))
+	if _, err := rand.Read(payload[8:]); err != nil {
+		panic(err)
+	}
+	nonce := leasePrefix + hex.EncodeToString(append(payload, l.sign(payload)...))
+	return &hmacLease{nonce: nonce, expiry: time.Unix(0, expiry.UnixNano())}
+}
+
+// FromValue construct a Lease from nonce value,
+// the nonce must be signed with the secret of the Lessor.
+func (l *HMACLessor) FromValue(nonce []byte) (Lease, error) {
+	raw, ok := decodeLease(nonce)
+	if !ok {
+		return nil, ErrNotLease
+	}
+	payload, mac := raw[:leasePayload], raw[leasePayload:]
+	if !hmac.Equal(mac, l.sign(payload)) {
+		return nil, ErrNotLease
+	}
+	return &hmacLease{
+		nonce:  string(nonce),
+		expiry: time.Unix(0, int64(binary.BigEndian.Uint64(payload))),
+	}, nil
+}
+
+// MustFromValue is like FromValue but panics if nonce value is not a Lease.
+func (l *HMACLessor) MustFromValue(nonce []byte) Lease {
+	lease, err := l.FromValue(nonce)
+	if err != nil {
+		panic(err)
+	}
+	return lease
+}
+
+// IsLease returns true if value is in the format of a Lease, false otherwise,
+// the Lease signed with another secret is still a Lease so that it's never taken as a value.
+func (l *HMACLessor) IsLease(value []byte) bool {
+	_, ok := decodeLease(value)
+	return ok
+}
+
+// Expiry returns the expiry time of value if it is in the format of a Lease,
+// it can be used as MemoryCache.LeaseExpiry.
+// Cacher takes over the Lease on a key once it expires.
+func (l *HMACLessor) Expiry(value []byte) (time.Time, bool) {
+	raw, ok := decodeLease(value)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(raw))), true
+}
+
+func (l *HMACLessor) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// decodeLease returns the payload and mac of value if it is in the format of a Lease.
+func decodeLease(value []byte) ([]byte, bool) {
+	if len(value) != leaseLen || !bytes.HasPrefix(value, []byte(leasePrefix)) {
+		return nil, false
+	}
+	raw := make([]byte, leasePayload+sha256.Size)
+	if _, err := hex.Decode(raw, value[len(leasePrefix):]); err != nil {
+		return nil, false
+	}
+	return raw, true
+}
+
+// Nonce is unique for each Lease.
+func (hl *hmacLease) Nonce() string {
+	return hl.nonce
+}
@@ -0,0 +1,367 @@
+package cached
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"time"
+)
+
+/*
+   https://github.com/memcached/memcached/blob/master/doc/protocol.txt
+*/
+
+type (
+	// Memcache is a Cache speaking the memcached text protocol over TCP.
+	Memcache struct {
+		addr        string
+		timeout     time.Duration
+		ttl         time.Duration
+		leaseExpiry func(value []byte) (time.Time, bool)
+		idle        chan *memcacheConn
+	}
+
+	// MemcacheConfig is the config for Memcache.
+	MemcacheConfig struct {
+		// Timeout is the dial and per-operation timeout, zero means no timeout.
+		Timeout time.Duration
+		// MaxIdleConns is the maximum number of idle connections kept open.
+		MaxIdleConns int
+		// TTL is the expiration time of values, zero means the values never expire.
+		TTL time.Duration
+		// LeaseExpiry optionally reports the expiry time of value if it is a Lease,
+		// the Lease is set to expire at that time instead of after TTL.
+		LeaseExpiry func(value []byte) (time.Time, bool)
+	}
+
+	// MemcacheOption applies config to MemcacheConfig.
+	MemcacheOption = func(*MemcacheConfig) error
+
+	memcacheConn struct {
+		nc net.Conn
+		rw *bufio.ReadWriter
+	}
+)
+
+var (
+	// ErrMalformedKey when the key is not a valid memcached key.
+	ErrMalformedKey = errors.New("malformed: key is too long or contains invalid characters")
+	// ErrNotStored when memcached does not store the value.
+	ErrNotStored = errors.New("memcache: item not stored")
+	// DefaultMemcacheConfig is the default config for Memcache.
+	DefaultMemcacheConfig = MemcacheConfig{
+		Timeout:      100 * time.Millisecond,
+		MaxIdleConns: 2,
+	}
+
+	crlf = []byte("\r\n")
+
+	resultStored    = []byte("STORED\r\n")
+	resultNotStored = []byte("NOT_STORED\r\n")
+	resultExists    = []byte("EXISTS\r\n")
+	resultNotFound  = []byte("NOT_FOUND\r\n")
+	resultDeleted   = []byte("DELETED\r\n")
+	resultEnd       = []byte("END\r\n")
+)
+
+var (
+	_ Cache      = (*Memcache)(nil)
+	_ KeyChecker = (*Memcache)(nil)
+	_ TTLSetter  = (*Memcache)(nil)
+	_ Deleter    = (*Memcache)(nil)
+)
+
+const (
+	// maxRelativeExptime is the maximum exptime in seconds memcached treats as relative,
+	// a larger exptime is an absolute unix time.
+	maxRelativeExptime = 60 * 60 * 24 * 30
+)
+
+// NewMemcache returns a new Memcache connecting to the memcached server at addr.
+func NewMemcache(addr string, options ...MemcacheOption) *Memcache {
+	c := DefaultMemcacheConfig
+	for _, option := range options {
+		if err := option(&c); err != nil {
+			log.Panicf("fail to apply MemcacheConfig -> %v\n", err)
+		}
+	}
+	if c.MaxIdleConns < 0 || c.Timeout < 0 || c.TTL < 0 {
+		log.Panicf("invalid MemcacheConfig -> %+v\n", c)
+	}
+	return &Memcache{
+		addr:        addr,
+		timeout:     c.Timeout,
+		ttl:         c.TTL,
+		leaseExpiry: c.LeaseExpiry,
+		idle:        make(chan *memcacheConn, c.MaxIdleConns),
+	}
+}
+
+// CheckKey returns ErrMalformedKey if key is not a valid memcached key,
+// which is too long or contains space or control characters.
+func (m *Memcache) CheckKey(key string) error {
+	if !legalKey(key) {
+		return ErrMalformedKey
+	}
+	return nil
+}
+
+// Get returns a list of []byte representing the values associated with the provided keys.
+// The value for a missing or malformed key is nil, see CheckKey,
+// nil is returned if memcached is unreachable.
+func (m *Memcache) Get(keys ...string) [][]byte {
+	valid := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if legalKey(key) {
+			valid = append(valid, key)
+		}
+	}
+	values := make([][]byte, len(keys))
+	if len(valid) == 0 {
+		return values
+	}
+
+	items := make(map[string]*memcacheItem, len(valid))
+	err := m.do(func(cn *memcacheConn) error {
+		return cn.retrieve("get", valid, func(it *memcacheItem) {
+			items[it.key] = it
+		})
+	})
+	if err != nil {
+		return nil
+	}
+	for i, key := range keys {
+		if it, ok := items[key]; ok {
+			values[i] = it.value
+		}
+	}
+	return values
+}
+
+// Set associates the provided value with the provided key in the cache layer.
+func (m *Memcache) Set(key string, value []byte) error {
+	return m.store("set", key, value, m.exptime(value), 0)
+}
+
+// SetTTL is like Set but the value expires after ttl instead of TTL.
+func (m *Memcache) SetTTL(key string, value []byte, ttl time.Duration) error {
+	return m.store("set", key, value, toExptime(ttl, time.Now().Add(ttl)), 0)
+}
+
+// AtomicAdd set the provided value for key if and only if the key has not already been set.
+// returns true if it succeeds, false otherwise, e.g. the key is malformed.
+func (m *Memcache) AtomicAdd(key string, value []byte) bool {
+	return m.store("add", key, value, m.exptime(value), 0) == nil
+}
+
+// AtomicCheckAndSet set the valueToSet for the provided key if and only if the key is currently associated with expectedValue.
+// returns true if it succeeds, false otherwise.
+func (m *Memcache) AtomicCheckAndSet(key string, expectedValue, valueToSet []byte) bool {
+	if !legalKey(key) {
+		return false
+	}
+
+	var current *memcacheItem
+	err := m.do(func(cn *memcacheConn) error {
+		return cn.retrieve("gets", []string{key}, func(it *memcacheItem) {
+			current = it
+		})
+	})
+	if err != nil || current == nil || !bytes.Equal(current.value, expectedValue) {
+		return false
+	}
+	return m.store("cas", key, valueToSet, m.exptime(valueToSet), current.casid) == nil
+}
+
+// Delete removes the provided key from memcached.
+func (m *Memcache) Delete(key string) error {
+	if !legalKey(key) {
+		return ErrMalformedKey
+	}
+	return m.do(func(cn *memcacheConn) error {
+		fmt.Fprintf(cn.rw, "delete %s\r\n", key)
+		if err := cn.rw.Flush(); err != nil {
+			return err
+		}
+
+		line, err := cn.rw.ReadSlice('\n')
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(line, resultDeleted) || bytes.Equal(line, resultNotFound) {
+			return nil
+		}
+		return fmt.Errorf("memcache: unexpected response line from delete: %q", line)
+	})
+}
+
+func (m *Memcache) store(verb, key string, value []byte, exptime int64, casid uint64) error {
+	if !legalKey(key) {
+		return ErrMalformedKey
+	}
+	return m.do(func(cn *memcacheConn) error {
+		if verb == "cas" {
+			fmt.Fprintf(cn.rw, "%s %s 0 %d %d %d\r\n", verb, key, exptime, len(value), casid)
+		} else {
+			fmt.Fprintf(cn.rw, "%s %s 0 %d %d\r\n", verb, key, exptime, len(value))
+		}
+		cn.rw.Write(value)
+		cn.rw.Write(crlf)
+		if err := cn.rw.Flush(); err != nil {
+			return err
+		}
+
+		line, err := cn.rw.ReadSlice('\n')
+		if err != nil {
+			return err
+		}
+		switch {
+		case bytes.Equal(line, resultStored):
+			return nil
+		case bytes.Equal(line, resultNotStored), bytes.Equal(line, resultExists), bytes.Equal(line, resultNotFound):
+			return ErrNotStored
+		}
+		return fmt.Errorf("memcache: unexpected response line from %s: %q", verb, line)
+	})
+}
+
+// exptime returns the memcached exptime for value.
+func (m *Memcache) exptime(value []byte) int64 {
+	if m.leaseExpiry != nil {
+		if expire, ok := m.leaseExpiry(value); ok {
+			return toExptime(time.Until(expire), expire)
+		}
+	}
+	if m.ttl == 0 {
+		return 0
+	}
+	return toExptime(m.ttl, time.Now().Add(m.ttl))
+}
+
+func toExptime(ttl time.Duration, expire time.Time) int64 {
+	if ttl <= 0 {
+		// negative exptime expires the item immediately.
+		return -1
+	}
+	secs := int64((ttl + time.Second - 1) / time.Second)
+	if secs > maxRelativeExptime {
+		return expire.Unix()
+	}
+	return secs
+}
+
+// do runs fn with a pooled connection,
+// the connection is closed instead of pooled if fn fails with an error other than ErrNotStored.
+func (m *Memcache) do(fn func(*memcacheConn) error) error {
+	cn, err := m.getConn()
+	if err != nil {
+		return err
+	}
+	if m.timeout > 0 {
+		cn.nc.SetDeadline(time.Now().Add(m.timeout))
+	}
+	err = fn(cn)
+	if err != nil && err != ErrNotStored {
+		cn.nc.Close()
+		return err
+	}
+	m.putConn(cn)
+	return err
+}
+
+func (m *Memcache) getConn() (*memcacheConn, error) {
+	select {
+	case cn := <-m.idle:
+		return cn, nil
+	default:
+	}
+	nc, err := net.DialTimeout("tcp", m.addr, m.timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &memcacheConn{
+		nc: nc,
+		rw: bufio.NewReadWriter(bufio.NewReader(nc), bufio.NewWriter(nc)),
+	}, nil
+}
+
+func (m *Memcache) putConn(cn *memcacheConn) {
+	select {
+	case m.idle <- cn:
+	default:
+		cn.nc.Close()
+	}
+}
+
+type memcacheItem struct {
+	key   string
+	value []byte
+	casid uint64
+}
+
+// retrieve sends get or gets for keys and calls fn for each item found.
+func (cn *memcacheConn) retrieve(verb string, keys []string, fn func(*memcacheItem)) error {
+	cn.rw.WriteString(verb)
+	for _, key := range keys {
+		cn.rw.WriteString(" ")
+		cn.rw.WriteString(key)
+	}
+	cn.rw.Write(crlf)
+	if err := cn.rw.Flush(); err != nil {
+		return err
+	}
+
+	for {
+		line, err := cn.rw.ReadSlice('\n')
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(line, resultEnd) {
+			return nil
+		}
+
+		// VALUE <key> <flags> <bytes> [<cas unique>]\r\n
+		fields := bytes.Fields(line)
+		if len(fields) < 4 || len(fields) > 5 || string(fields[0]) != "VALUE" {
+			return fmt.Errorf("memcache: unexpected line in %s response: %q", verb, line)
+		}
+		size, err := strconv.Atoi(string(fields[3]))
+		if err != nil || size < 0 {
+			return fmt.Errorf("memcache: malformed size in %s response: %q", verb, line)
+		}
+		it := &memcacheItem{key: string(fields[1])}
+		if len(fields) == 5 {
+			if it.casid, err = strconv.ParseUint(string(fields[4]), 10, 64); err != nil {
+				return fmt.Errorf("memcache: malformed cas in %s response: %q", verb, line)
+			}
+		}
+		it.value = make([]byte, size+2)
+		if _, err = io.ReadFull(cn.rw, it.value); err != nil {
+			return err
+		}
+		if !bytes.HasSuffix(it.value, crlf) {
+			return fmt.Errorf("memcache: corrupt %s response for key %s", verb, it.key)
+		}
+		it.value = it.value[:size]
+		fn(it)
+	}
+}
+
+// legalKey reports whether key is a valid memcached key,
+// which is at most 250 bytes without whitespace or control characters.
+func legalKey(key string) bool {
+	if len(key) == 0 || len(key) > 250 {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return false
+		}
+	}
+	return true
+}
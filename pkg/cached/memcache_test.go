@@ -0,0 +1,168 @@
+package cached_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andy2046/gopie/pkg/cached"
+)
+
+func TestMemcache(t *testing.T) {
+	s := newMemcacheServer()
+	defer s.Close()
+	c := cached.NewMemcache(s.Addr())
+
+	if !c.AtomicAdd("k1", []byte("v1")) {
+		t.Fatal("AtomicAdd should succeed for missing key")
+	}
+	if c.AtomicAdd("k1", []byte("v2")) {
+		t.Fatal("AtomicAdd should fail for existing key")
+	}
+	if c.AtomicCheckAndSet("k1", []byte("v2"), []byte("v3")) {
+		t.Fatal("AtomicCheckAndSet should fail for unexpected value")
+	}
+	if c.AtomicCheckAndSet("k2", nil, []byte("v3")) {
+		t.Fatal("AtomicCheckAndSet should fail for missing key")
+	}
+	if !c.AtomicCheckAndSet("k1", []byte("v1"), []byte("v3\r\nEND")) {
+		t.Fatal("AtomicCheckAndSet should succeed for expected value")
+	}
+	if err := c.Set("k2", []byte{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set("bad key", []byte("v")); err != cached.ErrMalformedKey {
+		t.Errorf("Set got %v want %v", err, cached.ErrMalformedKey)
+	}
+
+	got := c.Get("k1", "k3", "k2", strings.Repeat("k", 251))
+	if len(got) != 4 || string(got[0]) != "v3\r\nEND" || got[1] != nil || got[2] == nil || len(got[2]) != 0 || got[3] != nil {
+		t.Errorf("Get got %q", got)
+	}
+
+	if err := c.Delete("k1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Delete("k1"); err != nil {
+		t.Errorf("Delete missing key got %v", err)
+	}
+	if c.AtomicCheckAndSet("k1", []byte("v3\r\nEND"), []byte("v4")) {
+		t.Error("AtomicCheckAndSet should fail for deleted key")
+	}
+	if got := c.Get("k1"); got[0] != nil {
+		t.Errorf("Get deleted key got %q", got[0])
+	}
+}
+
+func TestMemcacheTTL(t *testing.T) {
+	s := newMemcacheServer()
+	defer s.Close()
+	lessor := cached.NewHMACLessor(nil, time.Millisecond)
+	c := cached.NewMemcache(s.Addr(), func(c *cached.MemcacheConfig) error {
+		c.TTL = time.Hour
+		c.LeaseExpiry = lessor.Expiry
+		return nil
+	})
+
+	c.Set("k", []byte("v"))
+	lease := lessor.NewLease()
+	time.Sleep(2 * time.Millisecond)
+	// the expired lease is stored to expire immediately.
+	c.Set("lease", []byte(lease.Nonce()))
+
+	// the value set with an elapsed ttl expires immediately.
+	c.SetTTL("ttl", []byte("v"), -time.Second)
+
+	got := c.Get("k", "lease", "ttl")
+	if string(got[0]) != "v" || got[1] != nil || got[2] != nil {
+		t.Errorf("Get got %q", got)
+	}
+}
+
+func TestMemcacheUnreachable(t *testing.T) {
+	s := newMemcacheServer()
+	c := cached.NewMemcache(s.Addr())
+	s.Close()
+
+	if got := c.Get("k"); got != nil {
+		t.Errorf("Get got %q want nil", got)
+	}
+	if c.Set("k", []byte("v")) == nil {
+		t.Error("Set should fail")
+	}
+	if c.AtomicAdd("k", []byte("v")) {
+		t.Error("AtomicAdd should fail")
+	}
+}
+
+func TestMemcacheCacher(t *testing.T) {
+	s := newMemcacheServer()
+	defer s.Close()
+
+	var calls int32
+	lessor := cached.NewHMACLessor([]byte("secret"), time.Second)
+	cache := cached.NewMemcache(s.Addr(), func(c *cached.MemcacheConfig) error {
+		c.MaxIdleConns = 8
+		c.LeaseExpiry = lessor.Expiry
+		return nil
+	})
+	c := cached.New(time.Millisecond, cache, lessor, func(key string) []byte {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		return []byte(key + "-from-truth")
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := c.Read("key"); string(got) != "key-from-truth" {
+				t.Errorf("Read got %s", got)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("TruthTeller called %d times want 1", n)
+	}
+}
+
+func TestMemcacheRandomSecret(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("HMACLessor with random secret should not be used with Memcache")
+		}
+	}()
+	cached.New(time.Millisecond, cached.NewMemcache("127.0.0.1:0"), cached.NewHMACLessor(nil, time.Second), func(key string) []byte {
+		return nil
+	})
+}
+
+func TestMemcacheMalformedKey(t *testing.T) {
+	s := newMemcacheServer()
+	defer s.Close()
+	c := cached.New(time.Millisecond, cached.NewMemcache(s.Addr()), cached.NewHMACLessor([]byte("secret"), time.Second), func(key string) []byte {
+		return []byte(key + "-from-truth")
+	})
+
+	// the key derived for the refresh lease is too long as well.
+	for _, key := range []string{"bad key", strings.Repeat("k", 250), strings.Repeat("k", 240)} {
+		if _, err := c.ReadContext(context.Background(), key); err != cached.ErrMalformedKey {
+			t.Errorf("%q: ReadContext got %v want %v", key, err, cached.ErrMalformedKey)
+		}
+		if _, err := c.ReadMany(context.Background(), "k", key); err != cached.ErrMalformedKey {
+			t.Errorf("%q: ReadMany got %v want %v", key, err, cached.ErrMalformedKey)
+		}
+		if err := c.Invalidate(key); err != cached.ErrMalformedKey {
+			t.Errorf("%q: Invalidate got %v want %v", key, err, cached.ErrMalformedKey)
+		}
+	}
+	if got, err := c.ReadContext(context.Background(), "k"); err != nil || string(got) != "k-from-truth" {
+		t.Errorf("ReadContext got %s %v", got, err)
+	}
+}
@@ -0,0 +1,224 @@
+package cached_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// memcacheServer is a minimal in-process memcached-compatible server
+	// supporting get, gets, set, add, cas and delete of the text protocol.
+	memcacheServer struct {
+		ln net.Listener
+		wg sync.WaitGroup
+
+		mu    sync.Mutex
+		items map[string]serverItem
+		casid uint64
+		conns map[net.Conn]struct{}
+	}
+
+	serverItem struct {
+		flags  string
+		value  []byte
+		casid  uint64
+		expire time.Time
+	}
+)
+
+func newMemcacheServer() *memcacheServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	s := &memcacheServer{
+		ln:    ln,
+		items: make(map[string]serverItem),
+		conns: make(map[net.Conn]struct{}),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+func (s *memcacheServer) Addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *memcacheServer) Close() {
+	s.ln.Close()
+	s.mu.Lock()
+	for nc := range s.conns {
+		nc.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *memcacheServer) serve() {
+	defer s.wg.Done()
+	for {
+		nc, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[nc] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go s.handle(nc)
+	}
+}
+
+func (s *memcacheServer) handle(nc net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, nc)
+		s.mu.Unlock()
+		nc.Close()
+		s.wg.Done()
+	}()
+
+	rw := bufio.NewReadWriter(bufio.NewReader(nc), bufio.NewWriter(nc))
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			rw.WriteString("ERROR\r\n")
+		} else {
+			switch fields[0] {
+			case "get", "gets":
+				s.retrieve(rw, fields[0] == "gets", fields[1:])
+			case "delete":
+				s.delete(rw, fields[1:])
+			case "set", "add", "cas":
+				if !s.store(rw, fields) {
+					return
+				}
+			default:
+				rw.WriteString("ERROR\r\n")
+			}
+		}
+		if rw.Flush() != nil {
+			return
+		}
+	}
+}
+
+func (s *memcacheServer) retrieve(w io.Writer, withCAS bool, keys []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		it, ok := s.get(key)
+		if !ok {
+			continue
+		}
+		if withCAS {
+			fmt.Fprintf(w, "VALUE %s %s %d %d\r\n", key, it.flags, len(it.value), it.casid)
+		} else {
+			fmt.Fprintf(w, "VALUE %s %s %d\r\n", key, it.flags, len(it.value))
+		}
+		w.Write(it.value)
+		io.WriteString(w, "\r\n")
+	}
+	io.WriteString(w, "END\r\n")
+}
+
+// store handles <verb> <key> <flags> <exptime> <bytes> [<cas unique>],
+// returns false if the connection should be closed.
+func (s *memcacheServer) store(rw *bufio.ReadWriter, fields []string) bool {
+	verb := fields[0]
+	if (verb == "cas" && len(fields) != 6) || (verb != "cas" && len(fields) != 5) {
+		rw.WriteString("ERROR\r\n")
+		return true
+	}
+	exptime, err1 := strconv.ParseInt(fields[3], 10, 64)
+	size, err2 := strconv.Atoi(fields[4])
+	if err1 != nil || err2 != nil || size < 0 {
+		rw.WriteString("CLIENT_ERROR bad command line format\r\n")
+		return false
+	}
+	data := make([]byte, size+2)
+	if _, err := io.ReadFull(rw, data); err != nil || !bytes.HasSuffix(data, []byte("\r\n")) {
+		rw.WriteString("CLIENT_ERROR bad data chunk\r\n")
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := fields[1]
+	current, exists := s.get(key)
+	switch verb {
+	case "add":
+		if exists {
+			rw.WriteString("NOT_STORED\r\n")
+			return true
+		}
+	case "cas":
+		casid, err := strconv.ParseUint(fields[5], 10, 64)
+		if err != nil {
+			rw.WriteString("CLIENT_ERROR bad command line format\r\n")
+			return false
+		}
+		if !exists {
+			rw.WriteString("NOT_FOUND\r\n")
+			return true
+		}
+		if current.casid != casid {
+			rw.WriteString("EXISTS\r\n")
+			return true
+		}
+	}
+
+	s.casid++
+	it := serverItem{flags: fields[2], value: data[:size], casid: s.casid}
+	switch {
+	case exptime < 0:
+		it.expire = time.Now()
+	case exptime > 60*60*24*30:
+		it.expire = time.Unix(exptime, 0)
+	case exptime > 0:
+		it.expire = time.Now().Add(time.Duration(exptime) * time.Second)
+	}
+	s.items[key] = it
+	rw.WriteString("STORED\r\n")
+	return true
+}
+
+func (s *memcacheServer) delete(w io.Writer, args []string) {
+	if len(args) != 1 {
+		io.WriteString(w, "ERROR\r\n")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.get(args[0]); !ok {
+		io.WriteString(w, "NOT_FOUND\r\n")
+		return
+	}
+	delete(s.items, args[0])
+	io.WriteString(w, "DELETED\r\n")
+}
+
+func (s *memcacheServer) get(key string) (serverItem, bool) {
+	it, ok := s.items[key]
+	if ok && !it.expire.IsZero() && !time.Now().Before(it.expire) {
+		delete(s.items, key)
+		return serverItem{}, false
+	}
+	return it, ok
+}
@@ -0,0 +1,253 @@
+package cached
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+type (
+	// MemoryCache is an in-memory Cache partitioned into independently locked shards by key hash.
+	MemoryCache struct {
+		// LeaseExpiry optionally reports the expiry time of value if it is a Lease,
+		// the Lease is removed from the cache once expired.
+		// Cacher takes over the expired Lease of HMACLessor even if it's not set.
+		// It must be set before the cache is used, e.g. to HMACLessor.Expiry.
+		LeaseExpiry func(value []byte) (time.Time, bool)
+		// MaxEntries is the maximum number of values in the cache rounded up to a multiple of shards,
+		// zero means no limit. An expired value or else an arbitrary value of the shard
+		// is evicted to add a new one.
+		// It must be set before the cache is used.
+		MaxEntries int
+
+		ttl    time.Duration
+		shards []*memoryShard
+		now    func() time.Time
+	}
+
+	memoryShard struct {
+		mu    sync.Mutex
+		items map[string]memoryItem
+	}
+
+	memoryItem struct {
+		value  []byte
+		expire time.Time
+	}
+)
+
+var (
+	_ Cache     = (*MemoryCache)(nil)
+	_ TTLSetter = (*MemoryCache)(nil)
+	_ Deleter   = (*MemoryCache)(nil)
+)
+
+// evictionSamples is the number of values sampled to find an expired one for eviction.
+const evictionSamples = 5
+
+// NewMemoryCache returns a new MemoryCache with the given number of shards,
+// values expire after ttl, if ttl is zero, the values never expire.
+func NewMemoryCache(shards int, ttl time.Duration) *MemoryCache {
+	if shards <= 0 {
+		panic("shards must be positive int")
+	}
+	if ttl < 0 {
+		panic("ttl can not be less than zero")
+	}
+	c := &MemoryCache{
+		ttl:    ttl,
+		shards: make([]*memoryShard, shards),
+		now:    time.Now,
+	}
+	for i := range c.shards {
+		c.shards[i] = &memoryShard{items: make(map[string]memoryItem)}
+	}
+	return c
+}
+
+// Get returns a list of []byte representing the values associated with the provided keys.
+// The value for a missing or expired key is nil.
+func (c *MemoryCache) Get(keys ...string) [][]byte {
+	values := make([][]byte, len(keys))
+	now := c.now()
+	for i, key := range keys {
+		s := c.shard(key)
+		s.mu.Lock()
+		if item, ok := s.get(key, now); ok {
+			values[i] = clone(item.value)
+		}
+		s.mu.Unlock()
+	}
+	return values
+}
+
+// Set associates the provided value with the provided key in the cache layer.
+func (c *MemoryCache) Set(key string, value []byte) error {
+	s := c.shard(key)
+	s.mu.Lock()
+	c.put(s, key, c.newItem(value))
+	s.mu.Unlock()
+	return nil
+}
+
+// SetTTL is like Set but the value expires after ttl instead of the ttl of the cache.
+func (c *MemoryCache) SetTTL(key string, value []byte, ttl time.Duration) error {
+	s := c.shard(key)
+	s.mu.Lock()
+	c.put(s, key, memoryItem{value: clone(value), expire: c.now().Add(ttl)})
+	s.mu.Unlock()
+	return nil
+}
+
+// AtomicAdd set the provided value for key if and only if the key has not already been set.
+// returns true if it succeeds, false otherwise.
+func (c *MemoryCache) AtomicAdd(key string, value []byte) bool {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.get(key, c.now()); ok {
+		return false
+	}
+	c.put(s, key, c.newItem(value))
+	return true
+}
+
+// AtomicCheckAndSet set the valueToSet for the provided key if and only if the key is currently associated with expectedValue.
+// returns true if it succeeds, false otherwise.
+func (c *MemoryCache) AtomicCheckAndSet(key string, expectedValue, valueToSet []byte) bool {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.get(key, c.now())
+	if !ok || !bytes.Equal(item.value, expectedValue) {
+		return false
+	}
+	c.put(s, key, c.newItem(valueToSet))
+	return true
+}
+
+// Delete removes the provided key from the cache.
+func (c *MemoryCache) Delete(key string) error {
+	s := c.shard(key)
+	s.mu.Lock()
+	delete(s.items, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// RemoveExpired removes all the expired values from the cache,
+// the expired values are otherwise removed lazily on access.
+func (c *MemoryCache) RemoveExpired() {
+	now := c.now()
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for key, item := range s.items {
+			if item.expired(now) {
+				delete(s.items, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Len returns the number of values in the cache, including the expired ones not yet removed.
+func (c *MemoryCache) Len() int {
+	n := 0
+	for _, s := range c.shards {
+		s.mu.Lock()
+		n += len(s.items)
+		s.mu.Unlock()
+	}
+	return n
+}
+
+func (c *MemoryCache) newItem(value []byte) memoryItem {
+	item := memoryItem{value: clone(value)}
+	if c.LeaseExpiry != nil {
+		if expire, ok := c.LeaseExpiry(value); ok {
+			item.expire = expire
+			return item
+		}
+	}
+	if c.ttl > 0 {
+		item.expire = c.now().Add(c.ttl)
+	}
+	return item
+}
+
+// put adds item to the shard s, evicting a value if the shard is full.
+func (c *MemoryCache) put(s *memoryShard, key string, item memoryItem) {
+	if _, ok := s.items[key]; !ok && c.MaxEntries > 0 {
+		// each shard holds its share of MaxEntries.
+		limit := (c.MaxEntries + len(c.shards) - 1) / len(c.shards)
+		if len(s.items) >= limit {
+			s.evict(c.now())
+		}
+	}
+	s.items[key] = item
+}
+
+func (c *MemoryCache) shard(key string) *memoryShard {
+	if len(c.shards) == 1 {
+		return c.shards[0]
+	}
+	return c.shards[fnv32a(key)%uint32(len(c.shards))]
+}
+
+// get returns the item not expired for key, the expired one is removed.
+func (s *memoryShard) get(key string, now time.Time) (memoryItem, bool) {
+	item, ok := s.items[key]
+	if !ok {
+		return item, false
+	}
+	if item.expired(now) {
+		delete(s.items, key)
+		return memoryItem{}, false
+	}
+	return item, true
+}
+
+// evict removes an expired value among a few sampled ones, or the first sampled one otherwise.
+func (s *memoryShard) evict(now time.Time) {
+	var victim string
+	n := 0
+	for key, item := range s.items {
+		if item.expired(now) {
+			victim = key
+			break
+		}
+		if n == 0 {
+			victim = key
+		}
+		if n++; n >= evictionSamples {
+			break
+		}
+	}
+	delete(s.items, victim)
+}
+
+func (item memoryItem) expired(now time.Time) bool {
+	return !item.expire.IsZero() && !now.Before(item.expire)
+}
+
+func clone(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append(make([]byte, 0, len(b)), b...)
+}
+
+func fnv32a(s string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	h := uint32(offset32)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= prime32
+	}
+	return h
+}
@@ -0,0 +1,246 @@
+package cached_test
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andy2046/gopie/pkg/cached"
+)
+
+func TestMemoryCache(t *testing.T) {
+	c := cached.NewMemoryCache(4, 0)
+
+	if !c.AtomicAdd("k1", []byte("v1")) {
+		t.Fatal("AtomicAdd should succeed for missing key")
+	}
+	if c.AtomicAdd("k1", []byte("v2")) {
+		t.Fatal("AtomicAdd should fail for existing key")
+	}
+	if c.AtomicCheckAndSet("k1", []byte("v2"), []byte("v3")) {
+		t.Fatal("AtomicCheckAndSet should fail for unexpected value")
+	}
+	if c.AtomicCheckAndSet("k2", nil, []byte("v3")) {
+		t.Fatal("AtomicCheckAndSet should fail for missing key")
+	}
+	if !c.AtomicCheckAndSet("k1", []byte("v1"), []byte("v3")) {
+		t.Fatal("AtomicCheckAndSet should succeed for expected value")
+	}
+	if err := c.Set("k2", []byte("v2")); err != nil {
+		t.Fatal(err)
+	}
+
+	got := c.Get("k1", "k3", "k2")
+	if len(got) != 3 || string(got[0]) != "v3" || got[1] != nil || string(got[2]) != "v2" {
+		t.Errorf("Get got %q", got)
+	}
+	got[0][0] = 'x'
+	if string(c.Get("k1")[0]) != "v3" {
+		t.Error("Get should return a copy of the value")
+	}
+
+	if err := c.Delete("k1"); err != nil {
+		t.Fatal(err)
+	}
+	if c.AtomicCheckAndSet("k1", []byte("v3"), []byte("v4")) {
+		t.Error("AtomicCheckAndSet should fail for deleted key")
+	}
+	if got := c.Get("k1"); got[0] != nil {
+		t.Errorf("Get deleted key got %q", got[0])
+	}
+}
+
+func TestMemoryCacheTTL(t *testing.T) {
+	c := cached.NewMemoryCache(1, 20*time.Millisecond)
+	c.Set("k", []byte("v"))
+	if !bytes.Equal(c.Get("k")[0], []byte("v")) {
+		t.Fatal("value should not expire yet")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if c.Get("k")[0] != nil {
+		t.Error("value should expire")
+	}
+	if !c.AtomicAdd("k", []byte("v2")) {
+		t.Error("AtomicAdd should succeed for expired key")
+	}
+
+	c.Set("k3", []byte("v3"))
+	time.Sleep(30 * time.Millisecond)
+	c.RemoveExpired()
+	if n := c.Len(); n != 0 {
+		t.Errorf("Len got %d want 0", n)
+	}
+}
+
+func TestHMACLessor(t *testing.T) {
+	l := cached.NewHMACLessor([]byte("secret"), time.Second)
+	lease := l.NewLease()
+	nonce := []byte(lease.Nonce())
+
+	if !l.IsLease(nonce) {
+		t.Fatal("nonce should be a lease")
+	}
+	if got := l.MustFromValue(nonce).Nonce(); got != lease.Nonce() {
+		t.Errorf("FromValue got %s want %s", got, lease.Nonce())
+	}
+	if l.NewLease().Nonce() == lease.Nonce() {
+		t.Error("nonce should be unique")
+	}
+	if expiry, ok := l.Expiry(nonce); !ok || time.Until(expiry) > time.Second {
+		t.Errorf("Expiry got %v %v", expiry, ok)
+	}
+
+	for _, v := range [][]byte{nil, []byte("value"), []byte("LEASE:value")} {
+		if l.IsLease(v) {
+			t.Errorf("%q should not be a lease", v)
+		}
+		if _, err := l.FromValue(v); err != cached.ErrNotLease {
+			t.Errorf("FromValue got %v want %v", err, cached.ErrNotLease)
+		}
+	}
+
+	// the forged lease and the lease signed by other secret are leases but can not be trusted.
+	forged := append([]byte(nil), nonce...)
+	forged[len(forged)-1] ^= 1
+	other := []byte(cached.NewHMACLessor([]byte("other"), time.Second).NewLease().Nonce())
+	for _, v := range [][]byte{forged, other} {
+		if !l.IsLease(v) {
+			t.Errorf("%q should be a lease", v)
+		}
+		if _, err := l.FromValue(v); err != cached.ErrNotLease {
+			t.Errorf("FromValue got %v want %v", err, cached.ErrNotLease)
+		}
+	}
+}
+
+func TestMemoryThunderingHerdProtection(t *testing.T) {
+	var calls int32
+	lessor := cached.NewHMACLessor(nil, time.Second)
+	cache := cached.NewMemoryCache(8, time.Minute)
+	cache.LeaseExpiry = lessor.Expiry
+	c := cached.New(time.Millisecond, cache, lessor, func(key string) []byte {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		return []byte(key + "-from-truth")
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := c.Read("key"); string(got) != "key-from-truth" {
+				t.Errorf("Read got %s", got)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("TruthTeller called %d times want 1", n)
+	}
+}
+
+func TestMemoryLeaseExpiry(t *testing.T) {
+	lessor := cached.NewHMACLessor(nil, 20*time.Millisecond)
+	cache := cached.NewMemoryCache(1, 0)
+	cache.LeaseExpiry = lessor.Expiry
+	c := cached.New(time.Millisecond, cache, lessor, func(key string) []byte {
+		return []byte(key + "-from-truth")
+	})
+
+	// the lease holder fails without releasing the lease.
+	cache.AtomicAdd("key", []byte(lessor.NewLease().Nonce()))
+
+	done := make(chan []byte)
+	go func() {
+		done <- c.Read("key")
+	}()
+	select {
+	case got := <-done:
+		if string(got) != "key-from-truth" {
+			t.Errorf("Read got %s", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Read should acquire the expired lease")
+	}
+}
+
+func TestMemoryLeaseExpiryByDefault(t *testing.T) {
+	lessor := cached.NewHMACLessor(nil, 20*time.Millisecond)
+	cache := cached.NewMemoryCache(1, 0)
+	c := cached.New(time.Millisecond, cache, lessor, func(key string) []byte {
+		return []byte(key + "-from-truth")
+	})
+
+	// the lease is never removed from the cache without LeaseExpiry.
+	cache.AtomicAdd("key", []byte(lessor.NewLease().Nonce()))
+
+	done := make(chan []byte)
+	go func() {
+		done <- c.Read("key")
+	}()
+	select {
+	case got := <-done:
+		if string(got) != "key-from-truth" {
+			t.Errorf("Read got %s", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Read should take over the expired lease")
+	}
+}
+
+func TestMemoryForeignLease(t *testing.T) {
+	cache := cached.NewMemoryCache(1, 0)
+	other := cached.NewHMACLessor([]byte("other"), 20*time.Millisecond)
+	c := cached.New(time.Millisecond, cache, cached.NewHMACLessor([]byte("secret"), time.Second), func(key string) []byte {
+		return []byte(key + "-from-truth")
+	})
+
+	// the lease of another process is never returned as the value.
+	cache.AtomicAdd("key", []byte(other.NewLease().Nonce()))
+	if got := c.Read("key"); string(got) != "key-from-truth" {
+		t.Errorf("Read got %s", got)
+	}
+}
+
+func TestMemoryLeaseStash(t *testing.T) {
+	lessor := cached.NewHMACLessor(nil, 10*time.Millisecond)
+	cache := cached.NewMemoryCache(1, 0)
+	c := cached.New(time.Millisecond, cache, lessor, func(key string) []byte {
+		return []byte(key + "-from-truth")
+	})
+
+	for i := 0; i < 100; i++ {
+		c.Read("key")
+		c.Invalidate("key")
+	}
+	// the values stashed behind the leases expire with the leases.
+	time.Sleep(20 * time.Millisecond)
+	cache.RemoveExpired()
+	if n := cache.Len(); n != 0 {
+		t.Errorf("Len got %d want 0", n)
+	}
+}
+
+func TestMemoryMaxEntries(t *testing.T) {
+	c := cached.NewMemoryCache(4, 0)
+	c.MaxEntries = 10
+	for i := 0; i < 100; i++ {
+		c.Set(fmt.Sprint(i), []byte("v"))
+		c.AtomicAdd(fmt.Sprint("add", i), []byte("v"))
+		c.SetTTL(fmt.Sprint("ttl", i), []byte("v"), time.Minute)
+	}
+	// each shard holds at most 3 values.
+	if n := c.Len(); n > 12 {
+		t.Errorf("Len got %d want at most 12", n)
+	}
+	c.Set("99", []byte("v2"))
+	if got := c.Get("99")[0]; string(got) != "v2" {
+		t.Errorf("Get got %s want v2", got)
+	}
+}
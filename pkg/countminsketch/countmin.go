@@ -2,46 +2,73 @@
 package countminsketch
 
 import (
-	"encoding/binary"
 	"errors"
-	"hash"
-	"hash/fnv"
 	"math"
+	"sync"
+	"sync/atomic"
 )
 
 // CountMinSketch struct.
 type CountMinSketch struct {
-	matrix [][]uint64  // count matrix
-	width  uint        // matrix width
-	depth  uint        // matrix depth
-	count  uint64      // total number of items added
-	hash   hash.Hash64 // hash function
+	count        uint64     // total number of items added, first for 64-bit alignment of atomic access
+	matrix       [][]uint64 // count matrix
+	width        uint       // matrix width
+	depth        uint       // matrix depth
+	seed         uint64     // hash seed
+	conservative bool       // conservative update
+	sync         bool       // counters are accessed atomically
+	mu           sync.Mutex // serializes conservative update in sync mode
 }
 
+type (
+	// Config is the config for CountMinSketch.
+	Config struct {
+		// ConservativeUpdate only increments the counters which are below the new estimate on Add,
+		// the estimate is still an upper bound of the frequency but with much less overestimation.
+		ConservativeUpdate bool
+		// Seed is the seed of the hash function,
+		// only the sketches with the same seed can be merged.
+		Seed uint64
+	}
+
+	// Option applies config to Config.
+	Option = func(*Config) error
+)
+
+// DefaultConfig is the default config for CountMinSketch.
+var DefaultConfig = Config{}
+
 // For a sketch matrix w x d with total sum of all counts N,
 // the estimate has error at most 2N/w, with probability at least 1-(1/2)^d.
 
 // New returns new Count-Min Sketch with the given `width` and `depth`.
-func New(width, depth uint) (*CountMinSketch, error) {
+// The sketch is not safe for concurrent use, see NewSync.
+func New(width, depth uint, options ...Option) (*CountMinSketch, error) {
 	if width < 1 || depth < 1 {
 		return nil, errors.New("Dimensions must be positive")
 	}
 
+	c := DefaultConfig
+	if err := setOption(&c, options...); err != nil {
+		return nil, err
+	}
+
 	matrix := make([][]uint64, depth)
 	for i := uint(0); i < depth; i++ {
 		matrix[i] = make([]uint64, width)
 	}
 
 	return &CountMinSketch{
-		matrix: matrix,
-		width:  width,
-		depth:  depth,
-		hash:   fnv.New64(),
+		matrix:       matrix,
+		width:        width,
+		depth:        depth,
+		seed:         c.Seed,
+		conservative: c.ConservativeUpdate,
 	}, nil
 }
 
 // NewGuess returns new Count-Min Sketch with the given error rate `epsilon` and confidence `delta`.
-func NewGuess(epsilon, delta float64) (*CountMinSketch, error) {
+func NewGuess(epsilon, delta float64, options ...Option) (*CountMinSketch, error) {
 	if epsilon <= 0 || epsilon >= 1 {
 		return nil, errors.New("epsilon must be in range (0, 1)")
 	}
@@ -52,11 +79,39 @@ func NewGuess(epsilon, delta float64) (*CountMinSketch, error) {
 	width, depth := uint(math.Ceil(math.E/epsilon)),
 		uint(math.Ceil(math.Log(1-delta)/math.Log(0.5)))
 
-	return New(width, depth)
+	return New(width, depth, options...)
+}
+
+// NewSync is like New but the sketch is safe for concurrent use,
+// the counters are updated atomically.
+// The conservative update is serialized by a mutex as it reads all the counters before raising them,
+// concurrent raises to the same estimate would lose counts otherwise.
+// Take note that Reset and Merge are not atomic as a whole,
+// the items added concurrently may be partially reset or merged.
+func NewSync(width, depth uint, options ...Option) (*CountMinSketch, error) {
+	c, err := New(width, depth, options...)
+	if err != nil {
+		return nil, err
+	}
+	c.sync = true
+	return c, nil
+}
+
+// NewSyncGuess is like NewGuess but the sketch is safe for concurrent use.
+func NewSyncGuess(epsilon, delta float64, options ...Option) (*CountMinSketch, error) {
+	c, err := NewGuess(epsilon, delta, options...)
+	if err != nil {
+		return nil, err
+	}
+	c.sync = true
+	return c, nil
 }
 
 // Count returns the number of items added to the sketch.
 func (c *CountMinSketch) Count() uint64 {
+	if c.sync {
+		return atomic.LoadUint64(&c.count)
+	}
 	return c.count
 }
 
@@ -67,13 +122,25 @@ func (c *CountMinSketch) Add(data []byte, count ...uint64) {
 		cnt = count[0]
 	}
 
-	lower, upper := hashn(data, c.hash)
+	lower, upper := hashn(data, c.seed)
 
-	for i := uint(0); i < c.depth; i++ {
-		c.matrix[i][(uint(lower)+uint(upper)*i)%c.width] += cnt
+	if c.conservative {
+		if c.sync {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+		}
+		// raise every counter to at least the new estimate.
+		estimate := c.estimate(lower, upper) + cnt
+		for i := uint(0); i < c.depth; i++ {
+			c.raise(&c.matrix[i][(uint(lower)+uint(upper)*i)%c.width], estimate)
+		}
+	} else {
+		for i := uint(0); i < c.depth; i++ {
+			c.add(&c.matrix[i][(uint(lower)+uint(upper)*i)%c.width], cnt)
+		}
 	}
 
-	c.count += cnt
+	c.add(&c.count, cnt)
 }
 
 // AddString add the `data` string to the sketch. `count` default to 1.
@@ -83,15 +150,15 @@ func (c *CountMinSketch) AddString(data string, count ...uint64) {
 
 // Estimate estimate the frequency of the `data`.
 func (c *CountMinSketch) Estimate(data []byte) uint64 {
-	var (
-		lower, upper = hashn(data, c.hash)
-		count        uint64
-	)
+	return c.estimate(hashn(data, c.seed))
+}
 
+func (c *CountMinSketch) estimate(lower, upper uint32) uint64 {
+	var count uint64
 	for i := uint(0); i < c.depth; i++ {
-		j := (uint(lower) + uint(upper)*i) % c.width
-		if i == 0 || c.matrix[i][j] < count {
-			count = c.matrix[i][j]
+		v := c.load(&c.matrix[i][(uint(lower)+uint(upper)*i)%c.width])
+		if i == 0 || v < count {
+			count = v
 		}
 	}
 
@@ -105,13 +172,25 @@ func (c *CountMinSketch) EstimateString(data string) uint64 {
 
 // Reset reset the sketch to its original state.
 func (c *CountMinSketch) Reset() {
-	matrix := make([][]uint64, c.depth)
 	for i := uint(0); i < c.depth; i++ {
-		matrix[i] = make([]uint64, c.width)
+		for j := uint(0); j < c.width; j++ {
+			c.store(&c.matrix[i][j], 0)
+		}
 	}
 
-	c.matrix = matrix
-	c.count = 0
+	c.store(&c.count, 0)
+}
+
+// Halve halves all the counters and the count to age the frequency,
+// the items added before are estimated at half of their frequency afterwards.
+func (c *CountMinSketch) Halve() {
+	for i := uint(0); i < c.depth; i++ {
+		for j := uint(0); j < c.width; j++ {
+			c.halve(&c.matrix[i][j])
+		}
+	}
+
+	c.halve(&c.count)
 }
 
 // Merge combines the sketch with another.
@@ -124,16 +203,30 @@ func (c *CountMinSketch) Merge(other *CountMinSketch) error {
 		return errors.New("matrix width must match")
 	}
 
+	if c.seed != other.seed {
+		return errors.New("hash seed must match")
+	}
+
 	for i := uint(0); i < c.depth; i++ {
 		for j := uint(0); j < c.width; j++ {
-			c.matrix[i][j] += other.matrix[i][j]
+			c.add(&c.matrix[i][j], other.load(&other.matrix[i][j]))
 		}
 	}
 
-	c.count += other.count
+	c.add(&c.count, other.Count())
 	return nil
 }
 
+// Conservative returns true if the sketch is in conservative update mode.
+func (c *CountMinSketch) Conservative() bool {
+	return c.conservative
+}
+
+// Seed returns the hash seed.
+func (c *CountMinSketch) Seed() uint64 {
+	return c.seed
+}
+
 // Depth returns the matrix depth.
 func (c *CountMinSketch) Depth() uint {
 	return c.depth
@@ -144,9 +237,78 @@ func (c *CountMinSketch) Width() uint {
 	return c.width
 }
 
-func hashn(data []byte, hasher hash.Hash64) (uint32, uint32) {
-	hasher.Reset()
-	hasher.Write(data)
-	sum := hasher.Sum(nil)
-	return binary.BigEndian.Uint32(sum[4:8]), binary.BigEndian.Uint32(sum[0:4])
+func (c *CountMinSketch) load(v *uint64) uint64 {
+	if c.sync {
+		return atomic.LoadUint64(v)
+	}
+	return *v
+}
+
+func (c *CountMinSketch) store(v *uint64, x uint64) {
+	if c.sync {
+		atomic.StoreUint64(v, x)
+		return
+	}
+	*v = x
+}
+
+func (c *CountMinSketch) add(v *uint64, x uint64) {
+	if c.sync {
+		atomic.AddUint64(v, x)
+		return
+	}
+	*v += x
+}
+
+func (c *CountMinSketch) halve(v *uint64) {
+	if !c.sync {
+		*v /= 2
+		return
+	}
+	for {
+		x := atomic.LoadUint64(v)
+		if atomic.CompareAndSwapUint64(v, x, x/2) {
+			return
+		}
+	}
+}
+
+// raise sets v to x if v is less than x,
+// the counters are only raised with mu held in sync mode.
+func (c *CountMinSketch) raise(v *uint64, x uint64) {
+	if c.load(v) < x {
+		c.store(v, x)
+	}
+}
+
+// hashn returns the lower and upper 32 bits of the seeded hash,
+// which is FNV-1 64 of the data finalized by SplitMix64 with the seed,
+// it's stateless so that the sketch can be shared by goroutines.
+func hashn(data []byte, seed uint64) (uint32, uint32) {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	h := uint64(offset64)
+	for _, c := range data {
+		h *= prime64
+		h ^= uint64(c)
+	}
+
+	h ^= seed
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return uint32(h), uint32(h >> 32)
+}
+
+func setOption(c *Config, options ...func(*Config) error) error {
+	for _, opt := range options {
+		if err := opt(c); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -98,6 +98,66 @@ func TestReset(t *testing.T) {
 	}
 }
 
+func TestHalve(t *testing.T) {
+	for _, sync := range []bool{false, true} {
+		cms, _ := New(64, 4)
+		cms.sync = sync
+		cms.AddString("a", 9)
+		cms.AddString("b", 4)
+		cms.AddString("c")
+
+		cms.Halve()
+
+		for key, want := range map[string]uint64{"a": 4, "b": 2, "c": 0} {
+			if got := cms.EstimateString(key); got < want || got > want+1 {
+				t.Errorf("%s: expected about %d, got %d", key, want, got)
+			}
+		}
+		if cms.Count() != 7 {
+			t.Errorf("expected 7, got %d", cms.Count())
+		}
+	}
+}
+
+func TestConservative(t *testing.T) {
+	conservative := func(c *Config) error {
+		c.ConservativeUpdate = true
+		return nil
+	}
+	cms, _ := New(64, 4)
+	cu, _ := New(64, 4, conservative)
+	if cms.Conservative() || !cu.Conservative() {
+		t.Fatal("expected conservative update mode only for cu")
+	}
+
+	freq := make(map[string]uint64)
+	for i := 0; i < 10000; i++ {
+		key := strconv.Itoa(i % 1000)
+		if i%10 == 0 {
+			key = "hot"
+		}
+		freq[key]++
+		cms.AddString(key)
+		cu.AddString(key, 1)
+	}
+
+	var errCMS, errCU uint64
+	for key, f := range freq {
+		e, ecu := cms.EstimateString(key), cu.EstimateString(key)
+		if ecu < f || ecu > e {
+			t.Fatalf("%s: expected %d <= %d <= %d", key, f, ecu, e)
+		}
+		errCMS += e - f
+		errCU += ecu - f
+	}
+	if errCU >= errCMS {
+		t.Errorf("expected less overestimation, got %d >= %d", errCU, errCMS)
+	}
+	if cu.Count() != 10000 {
+		t.Errorf("expected 10000, got %d", cu.Count())
+	}
+}
+
 func BenchmarkAdd(b *testing.B) {
 	b.StopTimer()
 	cms, _ := NewGuess(0.001, 0.99)
@@ -0,0 +1,97 @@
+package countminsketch
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+/*
+   the encoding is big endian:
+     magic   [4]byte "CMSK"
+     version uint8
+     flags   uint8, bit 0 is conservative update
+     width, depth, seed, count uint64
+     matrix  [depth][width]uint64
+*/
+
+const (
+	encodingVersion  uint8 = 1
+	headerSize             = 4 + 1 + 1 + 8*4
+	flagConservative       = 1 << 0
+)
+
+var (
+	magic = [4]byte{'C', 'M', 'S', 'K'}
+
+	// ErrInvalidEncoding when the data is not a valid encoded Count-Min Sketch.
+	ErrInvalidEncoding = errors.New("invalid count-min sketch encoding")
+)
+
+// Unmarshal decodes the Count-Min Sketch encoded by MarshalBinary,
+// the returned sketch is not safe for concurrent use,
+// decode into the sketch created by NewSync with UnmarshalBinary instead.
+func Unmarshal(data []byte) (*CountMinSketch, error) {
+	c := &CountMinSketch{}
+	if err := c.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler,
+// the dimensions and seed are included so that Merge across hosts is validated.
+func (c *CountMinSketch) MarshalBinary() ([]byte, error) {
+	data := make([]byte, headerSize+8*c.width*c.depth)
+	copy(data, magic[:])
+	data[4] = encodingVersion
+	if c.conservative {
+		data[5] |= flagConservative
+	}
+	binary.BigEndian.PutUint64(data[6:], uint64(c.width))
+	binary.BigEndian.PutUint64(data[14:], uint64(c.depth))
+	binary.BigEndian.PutUint64(data[22:], c.seed)
+	binary.BigEndian.PutUint64(data[30:], c.Count())
+
+	b := data[headerSize:]
+	for i := uint(0); i < c.depth; i++ {
+		for j := uint(0); j < c.width; j++ {
+			binary.BigEndian.PutUint64(b, c.load(&c.matrix[i][j]))
+			b = b[8:]
+		}
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler,
+// the sketch keeps its concurrency safety but UnmarshalBinary itself is not safe for concurrent use.
+func (c *CountMinSketch) UnmarshalBinary(data []byte) error {
+	if len(data) < headerSize || string(data[:4]) != string(magic[:]) ||
+		data[4] != encodingVersion || data[5]&^flagConservative != 0 {
+		return ErrInvalidEncoding
+	}
+
+	width := binary.BigEndian.Uint64(data[6:])
+	depth := binary.BigEndian.Uint64(data[14:])
+	size := uint64(len(data)-headerSize) / 8
+	if width < 1 || depth < 1 || width > size || depth > size/width ||
+		uint64(len(data)) != headerSize+8*width*depth {
+		return ErrInvalidEncoding
+	}
+
+	matrix := make([][]uint64, depth)
+	b := data[headerSize:]
+	for i := range matrix {
+		matrix[i] = make([]uint64, width)
+		for j := range matrix[i] {
+			matrix[i][j] = binary.BigEndian.Uint64(b)
+			b = b[8:]
+		}
+	}
+
+	c.matrix = matrix
+	c.width, c.depth = uint(width), uint(depth)
+	c.seed = binary.BigEndian.Uint64(data[22:])
+	c.count = binary.BigEndian.Uint64(data[30:])
+	c.conservative = data[5]&flagConservative != 0
+	return nil
+}
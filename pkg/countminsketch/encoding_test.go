@@ -0,0 +1,76 @@
+package countminsketch
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	seed := func(c *Config) error {
+		c.Seed = 42
+		c.ConservativeUpdate = true
+		return nil
+	}
+	cms, _ := New(100, 4, seed)
+	for i := 0; i < 1000; i++ {
+		cms.AddString(strconv.Itoa(i%77), uint64(i))
+	}
+
+	data, err := cms.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Width() != 100 || other.Depth() != 4 || other.Seed() != 42 ||
+		!other.Conservative() || other.Count() != cms.Count() {
+		t.Errorf("expected the same dimensions, seed, mode and count, got %d %d %d %v %d",
+			other.Width(), other.Depth(), other.Seed(), other.Conservative(), other.Count())
+	}
+	for i := 0; i < 100; i++ {
+		if a, b := cms.EstimateString(strconv.Itoa(i)), other.EstimateString(strconv.Itoa(i)); a != b {
+			t.Fatalf("%d: expected %d, got %d", i, a, b)
+		}
+	}
+	if data2, _ := other.MarshalBinary(); !bytes.Equal(data, data2) {
+		t.Error("expected byte-for-byte round trip")
+	}
+
+	// the decoded sketch is merged with the one of the same seed only.
+	if err := other.Merge(cms); err != nil {
+		t.Error(err)
+	}
+	if count := other.EstimateString("1"); count < 2*cms.EstimateString("1") {
+		t.Errorf("expected merged estimate, got %d", count)
+	}
+	unseeded, _ := New(100, 4)
+	if err := unseeded.Merge(other); err == nil {
+		t.Error("expected error for seed mismatch")
+	}
+
+	// decoding into the sketch created by NewSync keeps it safe for concurrent use.
+	s, _ := NewSync(1, 1)
+	if err := s.UnmarshalBinary(data); err != nil || !s.sync || s.Width() != 100 {
+		t.Errorf("expected sync sketch, got %v", err)
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	cms, _ := New(10, 2)
+	data, _ := cms.MarshalBinary()
+
+	for name, d := range map[string][]byte{
+		"empty":     {},
+		"truncated": data[:len(data)-1],
+		"trailing":  append(append([]byte{}, data...), 0),
+		"magic":     append([]byte("XXXX"), data[4:]...),
+		"width":     append(append(append([]byte{}, data[:6]...), 0, 0, 0, 0, 0, 0, 0, 0), data[14:]...),
+	} {
+		if _, err := Unmarshal(d); err != ErrInvalidEncoding {
+			t.Errorf("%s: expected %v, got %v", name, ErrInvalidEncoding, err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package countminsketch
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSyncConcurrent(t *testing.T) {
+	for _, conservative := range []bool{false, true} {
+		cms, _ := NewSyncGuess(0.001, 0.99, func(c *Config) error {
+			c.ConservativeUpdate = conservative
+			return nil
+		})
+
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					cms.AddString(strconv.Itoa(i % 10))
+					cms.EstimateString(strconv.Itoa(i % 10))
+				}
+			}()
+		}
+		wg.Wait()
+
+		if count := cms.Count(); count != 8000 {
+			t.Errorf("expected 8000, got %d", count)
+		}
+		for i := 0; i < 10; i++ {
+			if count := cms.EstimateString(strconv.Itoa(i)); count < 800 {
+				t.Errorf("%d: expected at least 800, got %d", i, count)
+			}
+		}
+	}
+}
+
+func BenchmarkSyncAddParallel(b *testing.B) {
+	cms, _ := NewSyncGuess(0.001, 0.99)
+	b.RunParallel(func(pb *testing.PB) {
+		data := []byte("0")
+		for i := 0; pb.Next(); i++ {
+			data[0] = byte(i)
+			cms.Add(data)
+		}
+	})
+}
@@ -0,0 +1,176 @@
+package countminsketch
+
+import (
+	"container/heap"
+	"errors"
+	"sort"
+	"sync"
+)
+
+type (
+	// TopK tracks the k heaviest keys of the stream with a Count-Min Sketch and a min-heap,
+	// the memory is bounded by the sketch and k keys.
+	// TopK is safe for concurrent use, the sketch must not be updated other than through the TopK.
+	TopK struct {
+		mu     sync.Mutex // guards the sketch, heap and index
+		sketch *CountMinSketch
+		k      int
+		heap   minHeap
+		index  map[string]*entry // keys in the heap
+	}
+
+	// Item is a key with its estimated frequency.
+	Item struct {
+		Key   string
+		Count uint64
+	}
+
+	entry struct {
+		Item
+		i int // index in the heap
+	}
+
+	minHeap []*entry
+)
+
+// NewTopK returns new TopK tracking the `k` heaviest keys added to the `sketch`,
+// the sketch should be empty and only updated through the TopK.
+func NewTopK(k uint, sketch *CountMinSketch) (*TopK, error) {
+	if k < 1 {
+		return nil, errors.New("k must be positive")
+	}
+	if sketch == nil {
+		return nil, errors.New("sketch must not be nil")
+	}
+
+	return &TopK{
+		sketch: sketch,
+		k:      int(k),
+		heap:   make(minHeap, 0, k),
+		index:  make(map[string]*entry, k),
+	}, nil
+}
+
+// Add add the `data` to the sketch and updates the heaviest keys. `count` default to 1.
+// It returns the estimated frequency of the `data`.
+func (t *TopK) Add(data []byte, count ...uint64) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.sketch.Add(data, count...)
+	estimate := t.sketch.Estimate(data)
+
+	if item, ok := t.index[string(data)]; ok {
+		item.Count = estimate
+		heap.Fix(&t.heap, item.i)
+		return estimate
+	}
+
+	if len(t.heap) < t.k {
+		item := &entry{Item: Item{Key: string(data), Count: estimate}}
+		heap.Push(&t.heap, item)
+		t.index[item.Key] = item
+		return estimate
+	}
+
+	// replace the lightest key.
+	if min := t.heap[0]; estimate > min.Count {
+		delete(t.index, min.Key)
+		min.Key, min.Count = string(data), estimate
+		heap.Fix(&t.heap, 0)
+		t.index[min.Key] = min
+	}
+	return estimate
+}
+
+// AddString add the `data` string to the sketch. `count` default to 1.
+func (t *TopK) AddString(data string, count ...uint64) uint64 {
+	return t.Add([]byte(data), count...)
+}
+
+// Top returns the heaviest keys in descending order of estimated frequency.
+func (t *TopK) Top() []Item {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.top()
+}
+
+func (t *TopK) top() []Item {
+	items := make([]Item, len(t.heap))
+	for i, item := range t.heap {
+		items[i] = item.Item
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return items[i].Key < items[j].Key
+	})
+	return items
+}
+
+// HeavyHitters returns the heaviest keys with estimated frequency at least `phi` of the total count,
+// in descending order of estimated frequency.
+// All the keys above the threshold are reported as long as there are at most k of them.
+func (t *TopK) HeavyHitters(phi float64) []Item {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	threshold := phi * float64(t.sketch.Count())
+	items := t.top()
+	for i, item := range items {
+		if float64(item.Count) < threshold {
+			return items[:i]
+		}
+	}
+	return items
+}
+
+// Estimate estimate the frequency of the `data`.
+func (t *TopK) Estimate(data []byte) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.sketch.Estimate(data)
+}
+
+// Sketch returns the underlying Count-Min Sketch, which is not guarded by the TopK.
+func (t *TopK) Sketch() *CountMinSketch {
+	return t.sketch
+}
+
+// Reset reset the sketch and the heaviest keys.
+func (t *TopK) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.sketch.Reset()
+	t.heap = t.heap[:0]
+	t.index = make(map[string]*entry, t.k)
+}
+
+func (h minHeap) Len() int { return len(h) }
+
+func (h minHeap) Less(i, j int) bool { return h[i].Count < h[j].Count }
+
+func (h minHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].i = i
+	h[j].i = j
+}
+
+func (h *minHeap) Push(x interface{}) {
+	item := x.(*entry)
+	item.i = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *minHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,96 @@
+package countminsketch
+
+import (
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestTopK(t *testing.T) {
+	cms, _ := NewGuess(0.001, 0.99)
+	topk, err := NewTopK(5, cms)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// key i is added i*100 times for the heavy keys, the rest is noise.
+	r := rand.New(rand.NewSource(1))
+	for i := 1; i <= 10; i++ {
+		for j := 0; j < i*100; j++ {
+			topk.AddString("heavy" + strconv.Itoa(i))
+			topk.AddString(strconv.Itoa(r.Intn(100000)))
+		}
+	}
+
+	top := topk.Top()
+	if len(top) != 5 {
+		t.Fatalf("expected 5, got %d", len(top))
+	}
+	for i, item := range top {
+		if want := "heavy" + strconv.Itoa(10-i); item.Key != want {
+			t.Errorf("expected %s, got %s", want, item.Key)
+		}
+		if want := uint64((10 - i) * 100); item.Count < want {
+			t.Errorf("%s: expected at least %d, got %d", item.Key, want, item.Count)
+		}
+	}
+
+	// 11000 items in total, heavy10 and heavy9 are above 8%.
+	hh := topk.HeavyHitters(0.08)
+	if len(hh) != 2 || hh[0].Key != "heavy10" || hh[1].Key != "heavy9" {
+		t.Errorf("expected heavy10 and heavy9, got %v", hh)
+	}
+
+	topk.Reset()
+	if len(topk.Top()) != 0 || topk.Estimate([]byte("heavy10")) != 0 {
+		t.Error("expected TopK to be empty")
+	}
+}
+
+func TestTopKConcurrent(t *testing.T) {
+	cms, _ := NewGuess(0.001, 0.99)
+	topk, _ := NewTopK(5, cms)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				topk.AddString(strconv.Itoa(i % 10))
+				topk.Top()
+			}
+		}()
+	}
+	wg.Wait()
+
+	top := topk.Top()
+	if len(top) != 5 || top[0].Count != 800 {
+		t.Errorf("expected 5 keys added 800 times, got %v", top)
+	}
+}
+
+func TestTopKInvalid(t *testing.T) {
+	cms, _ := New(10, 2)
+	if _, err := NewTopK(0, cms); err == nil {
+		t.Error("expected error for k 0")
+	}
+	if _, err := NewTopK(1, nil); err == nil {
+		t.Error("expected error for nil sketch")
+	}
+}
+
+func BenchmarkTopKAdd(b *testing.B) {
+	b.StopTimer()
+	cms, _ := NewGuess(0.001, 0.99)
+	topk, _ := NewTopK(100, cms)
+	data := make([][]byte, b.N)
+	for i := 0; i < b.N; i++ {
+		data[i] = []byte(strconv.Itoa(i % 10000))
+	}
+	b.StartTimer()
+
+	for n := 0; n < b.N; n++ {
+		topk.Add(data[n])
+	}
+}
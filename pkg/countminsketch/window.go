@@ -0,0 +1,194 @@
+package countminsketch
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Window estimates the frequency over a sliding window with rotating Count-Min Sketches,
+// the window is split into buckets of span and each bucket holds the items added within its span.
+// The estimate covers the last window plus the elapsed part of the current bucket,
+// so it's an upper bound of the frequency within the window.
+// Window is safe for concurrent use.
+type Window struct {
+	mu       sync.RWMutex
+	sketches []*CountMinSketch // buckets indexed by epoch % len(sketches)
+	epochs   []uint64          // epoch of the buckets
+	span     uint64            // span of a bucket in nanoseconds
+	window   time.Duration
+	now      func() time.Time // time.Now if nil, overridden in tests
+}
+
+// NewWindow returns new sliding window Count-Min Sketch over `window` split into `buckets`,
+// each bucket is a Count-Min Sketch with the given `width` and `depth`.
+// More buckets give a finer granularity of the window at the cost of memory.
+func NewWindow(window time.Duration, buckets, width, depth uint, options ...Option) (*Window, error) {
+	if buckets < 1 || uint64(window) < uint64(buckets) {
+		return nil, errors.New("buckets must be positive and window at least 1ns per bucket")
+	}
+
+	// one more bucket than the window to cover the whole window.
+	w := &Window{
+		sketches: make([]*CountMinSketch, buckets+1),
+		epochs:   make([]uint64, buckets+1),
+		span:     uint64(window) / uint64(buckets),
+		window:   window,
+	}
+	for i := range w.sketches {
+		c, err := NewSync(width, depth, options...)
+		if err != nil {
+			return nil, err
+		}
+		w.sketches[i] = c
+	}
+	return w, nil
+}
+
+// Add add the `data` to the current bucket. `count` default to 1.
+func (w *Window) Add(data []byte, count ...uint64) {
+	epoch := w.epoch()
+	w.mu.RLock()
+	c := w.bucket(epoch)
+	if c == nil {
+		w.mu.RUnlock()
+		w.rotate(epoch)
+		w.mu.RLock()
+		c = w.sketches[epoch%uint64(len(w.sketches))]
+	}
+	c.Add(data, count...)
+	w.mu.RUnlock()
+}
+
+// AddString add the `data` string to the current bucket. `count` default to 1.
+func (w *Window) AddString(data string, count ...uint64) {
+	w.Add([]byte(data), count...)
+}
+
+// Estimate estimate the frequency of the `data` within the window.
+func (w *Window) Estimate(data []byte) uint64 {
+	epoch := w.epoch()
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	lower, upper := hashn(data, w.sketches[0].seed)
+	var count uint64
+	for i, c := range w.sketches {
+		if w.live(i, epoch) {
+			count += c.estimate(lower, upper)
+		}
+	}
+	return count
+}
+
+// EstimateString estimate the frequency of the `data` string within the window.
+func (w *Window) EstimateString(data string) uint64 {
+	return w.Estimate([]byte(data))
+}
+
+// Count returns the number of items added within the window.
+func (w *Window) Count() uint64 {
+	epoch := w.epoch()
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var count uint64
+	for i, c := range w.sketches {
+		if w.live(i, epoch) {
+			count += c.Count()
+		}
+	}
+	return count
+}
+
+// Reset reset all the buckets.
+func (w *Window) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i, c := range w.sketches {
+		c.Reset()
+		w.epochs[i] = 0
+	}
+}
+
+// Merge combines the window with another of the same window, buckets, dimensions and seed,
+// the buckets of the same span are merged and the newer bucket of other replaces the older one.
+// Take note that merging two windows into each other concurrently may deadlock.
+func (w *Window) Merge(other *Window) error {
+	if w == other {
+		return errors.New("window can not be merged with itself")
+	}
+	if w.window != other.window || len(w.sketches) != len(other.sketches) {
+		return errors.New("window and buckets must match")
+	}
+	c, o := w.sketches[0], other.sketches[0]
+	if c.width != o.width || c.depth != o.depth || c.seed != o.seed {
+		return errors.New("matrix dimensions and hash seed must match")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+
+	for i, c := range w.sketches {
+		switch oe := other.epochs[i]; {
+		case oe < w.epochs[i]:
+			continue
+		case oe > w.epochs[i]:
+			c.Reset()
+			w.epochs[i] = oe
+		}
+		c.Merge(other.sketches[i])
+	}
+	return nil
+}
+
+// Window returns the duration of the window.
+func (w *Window) Window() time.Duration {
+	return w.window
+}
+
+// Buckets returns the number of buckets of the window.
+func (w *Window) Buckets() uint {
+	return uint(len(w.sketches) - 1)
+}
+
+func (w *Window) epoch() uint64 {
+	return uint64(w.timeNow().UnixNano()) / w.span
+}
+
+func (w *Window) timeNow() time.Time {
+	if w.now != nil {
+		return w.now()
+	}
+	return time.Now()
+}
+
+// bucket returns the sketch of epoch, nil if it's not rotated yet.
+func (w *Window) bucket(epoch uint64) *CountMinSketch {
+	i := epoch % uint64(len(w.sketches))
+	if w.epochs[i] != epoch {
+		return nil
+	}
+	return w.sketches[i]
+}
+
+// rotate resets the bucket of epoch if it holds an older epoch.
+func (w *Window) rotate(epoch uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	i := epoch % uint64(len(w.sketches))
+	if w.epochs[i] < epoch {
+		w.sketches[i].Reset()
+		w.epochs[i] = epoch
+	}
+}
+
+// live returns true if the bucket i is within the window at epoch.
+func (w *Window) live(i int, epoch uint64) bool {
+	e := w.epochs[i]
+	return e <= epoch && epoch-e < uint64(len(w.sketches))
+}
@@ -0,0 +1,123 @@
+package countminsketch
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (f *fakeClock) now() time.Time { return f.t }
+
+func (f *fakeClock) advance(d time.Duration) { f.t = f.t.Add(d) }
+
+func TestWindow(t *testing.T) {
+	window := 200 * time.Millisecond
+	clock := &fakeClock{time.Unix(0, 0)}
+	w, err := NewWindow(window, 4, 1000, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.now = clock.now
+	if w.Window() != window || w.Buckets() != 4 {
+		t.Errorf("expected %v and 4 buckets, got %v and %d", window, w.Window(), w.Buckets())
+	}
+
+	w.AddString("a", 10)
+	clock.advance(window / 2)
+	w.AddString("a", 5)
+	w.AddString("b")
+	if count := w.EstimateString("a"); count != 15 {
+		t.Errorf("expected 15, got %d", count)
+	}
+	if count := w.Count(); count != 16 {
+		t.Errorf("expected 16, got %d", count)
+	}
+
+	// the first items are kept for window.
+	clock.advance(window/2 - 1)
+	if count := w.EstimateString("a"); count != 15 {
+		t.Errorf("expected 15, got %d", count)
+	}
+
+	// the first items are out of the window within window * 1.25.
+	clock.advance(window/4 + 1)
+	if count := w.EstimateString("a"); count != 5 {
+		t.Errorf("expected 5, got %d", count)
+	}
+	if count := w.EstimateString("b"); count != 1 {
+		t.Errorf("expected 1, got %d", count)
+	}
+
+	w.Reset()
+	if count := w.Count(); count != 0 {
+		t.Errorf("expected 0, got %d", count)
+	}
+}
+
+func TestWindowMerge(t *testing.T) {
+	w, _ := NewWindow(time.Minute, 6, 1000, 4)
+	other, _ := NewWindow(time.Minute, 6, 1000, 4)
+	w.AddString("a", 2)
+	other.AddString("a", 3)
+	other.AddString("b")
+
+	if err := w.Merge(other); err != nil {
+		t.Fatal(err)
+	}
+	if count := w.EstimateString("a"); count != 5 {
+		t.Errorf("expected 5, got %d", count)
+	}
+	if count := w.EstimateString("b"); count != 1 {
+		t.Errorf("expected 1, got %d", count)
+	}
+
+	for name, o := range map[string]func() (*Window, error){
+		"window":  func() (*Window, error) { return NewWindow(time.Hour, 6, 1000, 4) },
+		"buckets": func() (*Window, error) { return NewWindow(time.Minute, 5, 1000, 4) },
+		"width":   func() (*Window, error) { return NewWindow(time.Minute, 6, 100, 4) },
+		"seed": func() (*Window, error) {
+			return NewWindow(time.Minute, 6, 1000, 4, func(c *Config) error {
+				c.Seed = 1
+				return nil
+			})
+		},
+	} {
+		o, _ := o()
+		if err := w.Merge(o); err == nil {
+			t.Errorf("%s: expected error for mismatch", name)
+		}
+	}
+	if err := w.Merge(w); err == nil {
+		t.Error("expected error for merging with itself")
+	}
+}
+
+func TestWindowConcurrent(t *testing.T) {
+	w, _ := NewWindow(20*time.Millisecond, 4, 1000, 4)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				w.AddString(strconv.Itoa(i % 10))
+				w.EstimateString(strconv.Itoa(i % 10))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestNewWindowInvalid(t *testing.T) {
+	if _, err := NewWindow(time.Minute, 0, 10, 2); err == nil {
+		t.Error("expected error for 0 buckets")
+	}
+	if _, err := NewWindow(time.Minute, 10, 0, 2); err == nil {
+		t.Error("expected error for 0 width")
+	}
+}
@@ -0,0 +1,342 @@
+// Package cuckoo implements a Cuckoo filter.
+package cuckoo
+
+/*
+   https://www.cs.cmu.edu/~dga/papers/cuckoo-conext2014.pdf
+*/
+
+import (
+	"errors"
+	"log"
+	"math"
+)
+
+type (
+	// Filter is the Cuckoo filter which supports deletion of entries,
+	// it has the same method set as bloom.CountingBloom.
+	Filter struct {
+		// table holds numBuckets * bucketSize fingerprints of fpBits bits each,
+		// a zero fingerprint is an empty slot.
+		table      []uint64
+		fpBits     uint8
+		bucketSize uint8
+		numBuckets uint64 // power of two
+		maxKicks   uint64
+		count      uint64
+		victim     victim
+		rnd        uint64 // xorshift state to choose the slot to kick out
+	}
+
+	// Config is the config for Filter.
+	Config struct {
+		// FingerprintBits is the size of fingerprint in bits, from 2 to 32.
+		FingerprintBits uint8
+		// BucketSize is the number of fingerprints per bucket, from 1 to 8.
+		BucketSize uint8
+		// MaxKicks is the maximum number of relocations before the filter is considered full.
+		MaxKicks uint64
+	}
+
+	// Option applies config to Config.
+	Option = func(*Config) error
+
+	// victim is the fingerprint evicted when the filter is full.
+	victim struct {
+		used  bool
+		index uint64
+		fp    uint32
+	}
+)
+
+var (
+	// ErrInvalidConfig when the config is out of range.
+	ErrInvalidConfig = errors.New("invalid cuckoo filter config")
+	// DefaultConfig is the default config for Filter,
+	// its false positive rate is about 0.012% at 95% load.
+	DefaultConfig = Config{
+		FingerprintBits: 16,
+		BucketSize:      4,
+		MaxKicks:        500,
+	}
+)
+
+// New creates a Cuckoo filter to hold at least capacity entries.
+func New(capacity uint64, options ...Option) *Filter {
+	c := DefaultConfig
+	if err := setOption(&c, options...); err != nil {
+		log.Panicf("fail to apply Config -> %v\n", err)
+	}
+	if c.FingerprintBits < 2 || c.FingerprintBits > 32 || c.BucketSize < 1 || c.BucketSize > 8 || c.MaxKicks == 0 {
+		log.Panicf("fail to apply Config -> %v\n", ErrInvalidConfig)
+	}
+
+	numBuckets := uint64(1)
+	for numBuckets*uint64(c.BucketSize) < capacity {
+		numBuckets <<= 1
+	}
+	return newFilter(numBuckets, c)
+}
+
+func newFilter(numBuckets uint64, c Config) *Filter {
+	bits := numBuckets * uint64(c.BucketSize) * uint64(c.FingerprintBits)
+	return &Filter{
+		table:      make([]uint64, (bits+63)/64),
+		fpBits:     c.FingerprintBits,
+		bucketSize: c.BucketSize,
+		numBuckets: numBuckets,
+		maxKicks:   c.MaxKicks,
+		rnd:        0x9e3779b97f4a7c15,
+	}
+}
+
+// Insert adds entry to the filter, returns false if the filter is full.
+// The same entry can be inserted up to 2 * BucketSize times.
+func (f *Filter) Insert(entry []byte) bool {
+	if f.victim.used {
+		return false
+	}
+
+	i, fp := f.indexAndFingerprint(entry)
+	f.place(i, fp)
+	f.count++
+	return true
+}
+
+// Lookup returns true if entry may be in the filter, false if it's definitely not.
+func (f *Filter) Lookup(entry []byte) bool {
+	i1, fp := f.indexAndFingerprint(entry)
+	i2 := f.altIndex(i1, fp)
+	if f.victim.used && f.victim.fp == fp && (f.victim.index == i1 || f.victim.index == i2) {
+		return true
+	}
+	return f.find(i1, fp) >= 0 || f.find(i2, fp) >= 0
+}
+
+// Delete removes one copy of entry from the filter, returns false if it's not found.
+// Deleting an entry which is not inserted may remove another entry with the same fingerprint.
+func (f *Filter) Delete(entry []byte) bool {
+	i1, fp := f.indexAndFingerprint(entry)
+	i2 := f.altIndex(i1, fp)
+	for _, i := range [2]uint64{i1, i2} {
+		if slot := f.find(i, fp); slot >= 0 {
+			f.set(i, uint64(slot), 0)
+			f.count--
+			f.reinsertVictim()
+			return true
+		}
+	}
+
+	if f.victim.used && f.victim.fp == fp && (f.victim.index == i1 || f.victim.index == i2) {
+		f.victim = victim{}
+		f.count--
+		return true
+	}
+	return false
+}
+
+// Count returns the number of entries in the filter.
+func (f *Filter) Count() uint64 {
+	return f.count
+}
+
+// LoadFactor returns the ratio of occupied slots.
+func (f *Filter) LoadFactor() float64 {
+	return float64(f.count) / float64(f.slots())
+}
+
+// Add adds entry to the filter, the entry is dropped if the filter is full.
+func (f *Filter) Add(entry []byte) {
+	f.Insert(entry)
+}
+
+// AddString adds entry to the filter.
+func (f *Filter) AddString(entry string) {
+	f.Insert([]byte(entry))
+}
+
+// Exist is the same as Lookup.
+func (f *Filter) Exist(entry []byte) bool {
+	return f.Lookup(entry)
+}
+
+// ExistString is the same as Lookup.
+func (f *Filter) ExistString(entry string) bool {
+	return f.Lookup([]byte(entry))
+}
+
+// Remove is the same as Delete.
+func (f *Filter) Remove(entry []byte) {
+	f.Delete(entry)
+}
+
+// RemoveString is the same as Delete.
+func (f *Filter) RemoveString(entry string) {
+	f.Delete([]byte(entry))
+}
+
+// FalsePositive returns the false positive probability at the current load.
+func (f *Filter) FalsePositive() float64 {
+	return f.GuessFalsePositive(f.count)
+}
+
+// GuessFalsePositive returns the false positive probability with n entries,
+// a lookup compares against the fingerprints in 2 buckets.
+func (f *Filter) GuessFalsePositive(n uint64) float64 {
+	load := math.Min(float64(n)/float64(f.slots()), 1)
+	compared := 2 * float64(f.bucketSize) * load
+	return 1 - math.Pow(1-1/(math.Exp2(float64(f.fpBits))-1), compared)
+}
+
+// M returns the size of the filter in bits.
+func (f *Filter) M() uint64 {
+	return f.slots() * uint64(f.fpBits)
+}
+
+// K returns the number of candidate buckets per entry, which is 2.
+func (f *Filter) K() uint64 {
+	return 2
+}
+
+// N returns the number of entries in the filter.
+func (f *Filter) N() uint64 {
+	return f.count
+}
+
+// Clear removes all the entries from the filter.
+func (f *Filter) Clear() {
+	for i := range f.table {
+		f.table[i] = 0
+	}
+	f.count = 0
+	f.victim = victim{}
+}
+
+func (f *Filter) slots() uint64 {
+	return f.numBuckets * uint64(f.bucketSize)
+}
+
+func (f *Filter) insertTo(i uint64, fp uint32) bool {
+	if slot := f.find(i, 0); slot >= 0 {
+		f.set(i, uint64(slot), fp)
+		return true
+	}
+	return false
+}
+
+// find returns the slot of fp in bucket i, -1 if not found.
+func (f *Filter) find(i uint64, fp uint32) int {
+	for slot := uint64(0); slot < uint64(f.bucketSize); slot++ {
+		if f.get(i, slot) == fp {
+			return int(slot)
+		}
+	}
+	return -1
+}
+
+func (f *Filter) reinsertVictim() {
+	if !f.victim.used {
+		return
+	}
+	v := f.victim
+	f.victim = victim{}
+	f.place(v.index, v.fp)
+}
+
+// place puts fp into bucket i or its alternate bucket,
+// relocating existing fingerprints to their alternate buckets if both are full.
+// If the filter is full, the last evicted fingerprint is kept as victim.
+func (f *Filter) place(i uint64, fp uint32) {
+	i2 := f.altIndex(i, fp)
+	if f.insertTo(i, fp) || f.insertTo(i2, fp) {
+		return
+	}
+
+	if f.random()&1 == 1 {
+		i = i2
+	}
+	for n := uint64(0); n < f.maxKicks; n++ {
+		slot := f.random() % uint64(f.bucketSize)
+		old := f.get(i, slot)
+		f.set(i, slot, fp)
+		fp = old
+		i = f.altIndex(i, fp)
+		if f.insertTo(i, fp) {
+			return
+		}
+	}
+	f.victim = victim{used: true, index: i, fp: fp}
+}
+
+func (f *Filter) get(i, slot uint64) uint32 {
+	pos := (i*uint64(f.bucketSize) + slot) * uint64(f.fpBits)
+	word, offset := pos/64, pos%64
+	v := f.table[word] >> offset
+	if offset+uint64(f.fpBits) > 64 {
+		v |= f.table[word+1] << (64 - offset)
+	}
+	return uint32(v & (1<<f.fpBits - 1))
+}
+
+func (f *Filter) set(i, slot uint64, fp uint32) {
+	pos := (i*uint64(f.bucketSize) + slot) * uint64(f.fpBits)
+	word, offset := pos/64, pos%64
+	mask := uint64(1)<<f.fpBits - 1
+	f.table[word] = f.table[word]&^(mask<<offset) | uint64(fp)<<offset
+	if offset+uint64(f.fpBits) > 64 {
+		shift := 64 - offset
+		f.table[word+1] = f.table[word+1]&^(mask>>shift) | uint64(fp)>>shift
+	}
+}
+
+// indexAndFingerprint returns the primary bucket index and the non-zero fingerprint of entry.
+func (f *Filter) indexAndFingerprint(entry []byte) (uint64, uint32) {
+	h := hash(entry)
+	fp := uint32((h>>32)%(1<<f.fpBits-1)) + 1
+	return h & (f.numBuckets - 1), fp
+}
+
+// altIndex returns the alternate bucket index of fp in bucket i,
+// altIndex(altIndex(i, fp), fp) == i.
+func (f *Filter) altIndex(i uint64, fp uint32) uint64 {
+	return (i ^ mix(uint64(fp))) & (f.numBuckets - 1)
+}
+
+func (f *Filter) random() uint64 {
+	f.rnd ^= f.rnd << 13
+	f.rnd ^= f.rnd >> 7
+	f.rnd ^= f.rnd << 17
+	return f.rnd
+}
+
+// hash is FNV-1a 64 finalized by mix.
+func hash(entry []byte) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	h := uint64(offset64)
+	for _, c := range entry {
+		h ^= uint64(c)
+		h *= prime64
+	}
+	return mix(h)
+}
+
+// mix is the SplitMix64 finalizer.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+func setOption(c *Config, options ...func(*Config) error) error {
+	for _, opt := range options {
+		if err := opt(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
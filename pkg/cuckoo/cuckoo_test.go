@@ -0,0 +1,216 @@
+package cuckoo_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/andy2046/gopie/pkg/bloom"
+	"github.com/andy2046/gopie/pkg/cuckoo"
+)
+
+var _ bloom.CountingBloom = (*cuckoo.Filter)(nil)
+
+func key(i uint32) []byte {
+	n := make([]byte, 4)
+	binary.BigEndian.PutUint32(n, i)
+	return n
+}
+
+func TestBasic(t *testing.T) {
+	f := cuckoo.New(1000)
+	e1 := []byte("Boss")
+	e2 := []byte("Joke")
+	if !f.Insert(e1) {
+		t.Fatal("Insert should succeed.")
+	}
+	if !f.Lookup(e1) {
+		t.Errorf("%q should Exist.", e1)
+	}
+	if f.Lookup(e2) {
+		t.Errorf("%q should not Exist.", e2)
+	}
+	if f.Delete(e2) {
+		t.Errorf("%q should not be deleted.", e2)
+	}
+
+	f.AddString("Boss")
+	if f.Count() != 2 || f.N() != 2 {
+		t.Errorf("Count got %d want 2", f.Count())
+	}
+	f.RemoveString("Boss")
+	if !f.ExistString("Boss") {
+		t.Error("Boss added twice should Exist after removed once.")
+	}
+	f.Remove(e1)
+	if f.Exist(e1) {
+		t.Error("Boss should not Exist after removed twice.")
+	}
+	if f.Count() != 0 {
+		t.Errorf("Count got %d want 0", f.Count())
+	}
+}
+
+func TestConfig(t *testing.T) {
+	for _, c := range []cuckoo.Config{
+		{FingerprintBits: 8, BucketSize: 4, MaxKicks: 500},
+		{FingerprintBits: 12, BucketSize: 2, MaxKicks: 500},
+		{FingerprintBits: 32, BucketSize: 8, MaxKicks: 500},
+	} {
+		c := c
+		f := cuckoo.New(10000, func(cfg *cuckoo.Config) error {
+			*cfg = c
+			return nil
+		})
+		for i := uint32(0); i < 8000; i++ {
+			if !f.Insert(key(i)) {
+				t.Fatalf("%+v: Insert %d should succeed at load %f", c, i, f.LoadFactor())
+			}
+		}
+		for i := uint32(0); i < 8000; i++ {
+			if !f.Lookup(key(i)) {
+				t.Fatalf("%+v: %d should Exist.", c, i)
+			}
+		}
+
+		fp := 0
+		for i := uint32(8000); i < 108000; i++ {
+			if f.Lookup(key(i)) {
+				fp++
+			}
+		}
+		if rate, want := float64(fp)/100000, f.FalsePositive(); rate > 2*want+0.0001 {
+			t.Errorf("%+v: false positive rate got %f want about %f", c, rate, want)
+		}
+
+		for i := uint32(0); i < 8000; i++ {
+			if !f.Delete(key(i)) {
+				t.Fatalf("%+v: %d should be deleted.", c, i)
+			}
+		}
+		if f.Count() != 0 {
+			t.Errorf("%+v: Count got %d want 0", c, f.Count())
+		}
+	}
+}
+
+func TestFull(t *testing.T) {
+	f := cuckoo.New(1024)
+	var inserted uint32
+	for f.Insert(key(inserted)) {
+		inserted++
+	}
+	if f.LoadFactor() < 0.9 {
+		t.Errorf("load factor got %f want at least 0.9", f.LoadFactor())
+	}
+	for i := uint32(0); i < inserted; i++ {
+		if !f.Lookup(key(i)) {
+			t.Fatalf("%d should Exist.", i)
+		}
+	}
+
+	// the filter accepts inserts again after deletion.
+	for i := uint32(0); i < 10; i++ {
+		f.Delete(key(i))
+	}
+	if !f.Insert(key(inserted)) {
+		t.Error("Insert should succeed after deletion.")
+	}
+
+	f.Clear()
+	if f.Count() != 0 || f.Lookup(key(inserted)) {
+		t.Error("filter should be empty after Clear.")
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	f := cuckoo.New(1000, func(c *cuckoo.Config) error {
+		c.FingerprintBits = 12
+		return nil
+	})
+	for i := uint32(0); i < 1500; i++ {
+		f.Insert(key(i))
+	}
+
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f2, err := cuckoo.Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f2.Count() != f.Count() || f2.M() != f.M() {
+		t.Errorf("Count/M got %d/%d want %d/%d", f2.Count(), f2.M(), f.Count(), f.M())
+	}
+	for i := uint32(0); i < 3000; i++ {
+		if f.Lookup(key(i)) != f2.Lookup(key(i)) {
+			t.Fatalf("Lookup(%d) mismatch", i)
+		}
+	}
+	if data2, _ := f2.MarshalBinary(); !bytes.Equal(data, data2) {
+		t.Error("round trip is not byte-for-byte")
+	}
+
+	var buf bytes.Buffer
+	n, err := f.WriteTo(&buf)
+	if err != nil || n != int64(len(data)) {
+		t.Errorf("WriteTo got %d %v", n, err)
+	}
+	var f3 cuckoo.Filter
+	if n, err = f3.ReadFrom(&buf); err != nil || n != int64(len(data)) {
+		t.Errorf("ReadFrom got %d %v", n, err)
+	}
+
+	for name, d := range map[string][]byte{
+		"empty":     {},
+		"truncated": data[:len(data)-1],
+		"trailing":  append(append([]byte{}, data...), 0),
+		"magic":     append([]byte("XXXX"), data[4:]...),
+	} {
+		if _, err := cuckoo.Unmarshal(d); err != cuckoo.ErrInvalidEncoding {
+			t.Errorf("%s: got %v want %v", name, err, cuckoo.ErrInvalidEncoding)
+		}
+	}
+}
+
+func TestUnmarshalTruncated(t *testing.T) {
+	data, _ := cuckoo.New(1000).MarshalBinary()
+	// the header claims 2^34 buckets without the table.
+	d := append([]byte{}, data[:52]...)
+	binary.BigEndian.PutUint64(d[8:], 1<<34)
+
+	if _, err := cuckoo.Unmarshal(d); err != cuckoo.ErrInvalidEncoding {
+		t.Errorf("Unmarshal got %v want %v", err, cuckoo.ErrInvalidEncoding)
+	}
+	// the reader of unknown length fails once the bytes run out.
+	var f cuckoo.Filter
+	if _, err := f.ReadFrom(io.MultiReader(bytes.NewReader(d), bytes.NewReader(data[52:]))); err != cuckoo.ErrInvalidEncoding {
+		t.Errorf("ReadFrom got %v want %v", err, cuckoo.ErrInvalidEncoding)
+	}
+}
+
+func BenchmarkInsert(b *testing.B) {
+	f := cuckoo.New(uint64(b.N))
+	n := make([]byte, 4)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		binary.BigEndian.PutUint32(n, uint32(i))
+		f.Insert(n)
+	}
+}
+
+func BenchmarkLookup(b *testing.B) {
+	f := cuckoo.New(1 << 20)
+	n := make([]byte, 4)
+	for i := 0; i < 1<<19; i++ {
+		binary.BigEndian.PutUint32(n, uint32(i))
+		f.Insert(n)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		binary.BigEndian.PutUint32(n, uint32(i))
+		f.Lookup(n)
+	}
+}
@@ -0,0 +1,147 @@
+package cuckoo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+/*
+   the encoding is big endian:
+     magic       [4]byte "CKOF"
+     version     uint8
+     fpBits      uint8
+     bucketSize  uint8
+     victim used uint8
+     numBuckets, maxKicks, count, victim index uint64
+     victim fp   uint32
+     rnd         uint64
+     table       [len(table)]uint64
+*/
+
+const (
+	encodingVersion uint8 = 1
+	headerSize            = 4 + 4 + 8*4 + 4 + 8
+	maxNumBuckets         = 1 << 40
+	minChunkSize          = 4096 // minimum size of the buffer growth on decoding
+)
+
+var (
+	magic = [4]byte{'C', 'K', 'O', 'F'}
+
+	// ErrInvalidEncoding when the data is not a valid encoded Cuckoo filter.
+	ErrInvalidEncoding = errors.New("invalid cuckoo filter encoding")
+)
+
+// Unmarshal decodes the Cuckoo filter encoded by MarshalBinary.
+func Unmarshal(data []byte) (*Filter, error) {
+	f := &Filter{}
+	if err := f.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := f.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (f *Filter) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	if _, err := f.ReadFrom(r); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return ErrInvalidEncoding
+	}
+	return nil
+}
+
+// WriteTo implements io.WriterTo.
+func (f *Filter) WriteTo(w io.Writer) (int64, error) {
+	buf := make([]byte, headerSize, headerSize+8*len(f.table))
+	copy(buf, magic[:])
+	buf[4], buf[5], buf[6] = encodingVersion, f.fpBits, f.bucketSize
+	if f.victim.used {
+		buf[7] = 1
+	}
+	binary.BigEndian.PutUint64(buf[8:], f.numBuckets)
+	binary.BigEndian.PutUint64(buf[16:], f.maxKicks)
+	binary.BigEndian.PutUint64(buf[24:], f.count)
+	binary.BigEndian.PutUint64(buf[32:], f.victim.index)
+	binary.BigEndian.PutUint32(buf[40:], f.victim.fp)
+	binary.BigEndian.PutUint64(buf[44:], f.rnd)
+
+	var word [8]byte
+	for _, v := range f.table {
+		binary.BigEndian.PutUint64(word[:], v)
+		buf = append(buf, word[:]...)
+	}
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// ReadFrom implements io.ReaderFrom.
+func (f *Filter) ReadFrom(r io.Reader) (int64, error) {
+	var header [headerSize]byte
+	n, err := io.ReadFull(r, header[:])
+	if err != nil {
+		return int64(n), ErrInvalidEncoding
+	}
+
+	g := Filter{
+		fpBits:     header[5],
+		bucketSize: header[6],
+		numBuckets: binary.BigEndian.Uint64(header[8:]),
+		maxKicks:   binary.BigEndian.Uint64(header[16:]),
+		count:      binary.BigEndian.Uint64(header[24:]),
+		victim: victim{
+			used:  header[7] == 1,
+			index: binary.BigEndian.Uint64(header[32:]),
+			fp:    binary.BigEndian.Uint32(header[40:]),
+		},
+		rnd: binary.BigEndian.Uint64(header[44:]),
+	}
+	if !bytes.Equal(header[:4], magic[:]) || header[4] != encodingVersion || header[7] > 1 ||
+		g.fpBits < 2 || g.fpBits > 32 || g.bucketSize < 1 || g.bucketSize > 8 || g.maxKicks == 0 ||
+		g.numBuckets == 0 || g.numBuckets > maxNumBuckets || g.numBuckets&(g.numBuckets-1) != 0 ||
+		g.victim.index >= g.numBuckets || g.rnd == 0 {
+		return int64(n), ErrInvalidEncoding
+	}
+
+	bits := g.numBuckets * uint64(g.bucketSize) * uint64(g.fpBits)
+	size := 8 * ((bits + 63) / 64)
+	if l, ok := r.(interface{ Len() int }); ok && uint64(l.Len()) < size {
+		return int64(n), ErrInvalidEncoding
+	}
+	// the buffer grows as the bytes arrive
+	// so that a corrupt header can not allocate much more than the input.
+	var data []byte
+	for uint64(len(data)) < size {
+		chunk := size - uint64(len(data))
+		if grow := uint64(len(data)) + minChunkSize; chunk > grow {
+			chunk = grow
+		}
+		l := len(data)
+		data = append(data, make([]byte, chunk)...)
+		m, err := io.ReadFull(r, data[l:])
+		n += m
+		if err != nil {
+			return int64(n), ErrInvalidEncoding
+		}
+	}
+	g.table = make([]uint64, len(data)/8)
+	for i := range g.table {
+		g.table[i] = binary.BigEndian.Uint64(data[8*i:])
+	}
+
+	*f = g
+	return int64(n), nil
+}
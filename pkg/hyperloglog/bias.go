@@ -0,0 +1,41 @@
+// Code generated by biasgen.go; DO NOT EDIT.
+
+package hyperloglog
+
+// rawEstimateData is the mean raw estimate at the sampled cardinalities for precision 4 to 18.
+var rawEstimateData = [...][]float64{
+	{11.24, 11.72, 12.22, 12.74, 13.27, 13.82, 14.38, 14.96, 15.55, 16.16, 16.79, 17.43, 18.08, 18.75, 19.42, 20.13, 20.85, 21.58, 22.32, 23.08, 23.85, 24.64, 25.44, 26.25, 27.08, 27.91, 28.76, 29.61, 30.46, 31.33, 32.19, 33.07, 33.97, 34.85, 35.77, 36.69, 37.63, 38.54, 39.49, 40.43, 41.34, 42.3, 43.26, 44.22, 45.22, 46.17, 47.15, 48.13, 49.14, 50.17, 51.16, 52.11, 53.05, 53.96, 54.94, 55.88, 56.85, 57.88, 58.86, 59.88, 60.83, 61.78, 62.79, 63.82, 64.79, 65.8, 66.83, 67.83, 68.82, 69.83, 70.8, 71.89, 72.85, 73.88, 74.87, 75.83, 76.79, 77.76, 78.77, 79.76},
+	{22.78, 23.26, 23.75, 24.26, 24.76, 25.27, 25.79, 26.32, 26.85, 27.39, 27.95, 28.51, 29.07, 29.64, 30.22, 30.8, 31.4, 32, 32.61, 33.23, 33.86, 34.5, 35.13, 35.78, 36.43, 37.1, 37.77, 38.45, 39.13, 39.83, 40.52, 41.22, 41.93, 42.66, 43.39, 44.13, 44.87, 45.61, 46.36, 47.11, 47.88, 48.65, 49.43, 50.23, 51.01, 51.79, 52.6, 53.43, 54.24, 55.07, 55.89, 56.7, 57.52, 58.37, 59.2, 60.05, 60.89, 61.75, 62.61, 63.46, 64.31, 65.18, 66.06, 66.95, 67.88, 68.77, 69.66, 70.57, 71.48, 72.37, 73.29, 74.21, 75.12, 76.01, 76.92, 77.84, 78.74, 79.68, 80.64, 81.57, 82.5, 83.45, 84.41, 85.35, 86.24, 87.21, 88.19, 89.13, 90.1, 91.02, 92.01, 92.95, 93.89, 94.82, 95.8, 96.8, 97.8, 98.81, 99.76, 100.72, 101.69, 102.67, 103.66, 104.66, 105.62, 106.61, 107.58, 108.58, 109.54, 110.49, 111.42, 112.42, 113.41, 114.38, 115.41, 116.41, 117.41, 118.33, 119.34, 120.34, 121.3, 122.3, 123.29, 124.25, 125.24, 126.2, 127.14, 128.16, 129.22, 130.23, 131.21, 132.27, 133.22, 134.22, 135.24, 136.26, 137.28, 138.29, 139.3, 140.29, 141.31, 142.3, 143.26, 144.25, 145.25, 146.24, 147.22, 148.25, 149.28, 150.3, 151.32, 152.34, 153.35, 154.37, 155.29, 156.3, 157.33, 158.34, 159.34, 160.36},
+	{46.83, 48.3, 49.81, 51.36, 52.93, 54.54, 56.18, 57.86, 59.56, 61.32, 63.08, 64.89, 66.72, 68.6, 70.49, 72.41, 74.36, 76.36, 78.39, 80.46, 82.55, 84.67, 86.82, 89.02, 91.23, 93.48, 95.74, 98.02, 100.35, 102.72, 105.06, 107.43, 109.85, 112.32, 114.81, 117.32, 119.8, 122.31, 124.84, 127.36, 129.98, 132.58, 135.24, 137.89, 140.57, 143.28, 145.95, 148.7, 151.42, 154.18, 156.97, 159.75, 162.55, 165.26, 168.03, 170.85, 173.71, 176.51, 179.32, 182.15, 185.08, 187.86, 190.73, 193.58, 196.47, 199.38, 202.25, 205.13, 208.09, 210.93, 213.93, 216.86, 219.83, 222.78, 225.74, 228.61, 231.66, 234.48, 237.43, 240.33, 243.33, 246.27, 249.26, 252.26, 255.19, 258.09, 261.01, 263.98, 266.87, 269.86, 272.84, 275.74, 278.69, 281.75, 284.7, 287.64, 290.58, 293.59, 296.59, 299.56, 302.55, 305.55, 308.56, 311.53, 314.47, 317.48},
+	{94.46, 97.44, 100.47, 103.57, 106.74, 109.97, 113.27, 116.63, 120.07, 123.59, 127.14, 130.77, 134.45, 138.21, 142.03, 145.9, 149.82, 153.79, 157.85, 161.95, 166.13, 170.36, 174.67, 179, 183.39, 187.87, 192.35, 196.92, 201.53, 206.16, 210.89, 215.64, 220.42, 225.31, 230.2, 235.18, 240.24, 245.31, 250.37, 255.46, 260.64, 265.84, 271.1, 276.37, 281.71, 287.08, 292.44, 297.87, 303.28, 308.65, 314.08, 319.55, 325.04, 330.51, 336.03, 341.57, 347.18, 352.83, 358.42, 364.15, 369.88, 375.45, 381.18, 386.99, 392.91, 398.7, 404.58, 410.34, 416.15, 421.96, 427.9, 433.71, 439.49, 445.36, 451.32, 457.18, 463.09, 469.13, 474.97, 480.76, 486.76, 492.64, 498.59, 504.49, 510.44, 516.33, 522.23, 528.14, 534.05, 539.89, 545.86, 551.7, 557.66, 563.63, 569.52, 575.48, 581.48, 587.44, 593.28, 599.29, 605.34, 611.39, 617.4, 623.34, 629.47, 635.63},
+	{189.7, 195.64, 201.72, 207.92, 214.27, 220.73, 227.33, 234.08, 240.92, 247.91, 255.04, 262.29, 269.66, 277.15, 284.77, 292.51, 300.39, 308.4, 316.53, 324.76, 333.13, 341.67, 350.25, 358.94, 367.73, 376.69, 385.68, 394.82, 404.09, 413.4, 422.82, 432.41, 442.01, 451.69, 461.47, 471.37, 481.44, 491.55, 501.66, 511.84, 522.11, 532.5, 542.89, 553.43, 564.05, 574.68, 585.38, 596.16, 607, 618.01, 628.94, 639.83, 651.02, 662.07, 673.25, 684.35, 695.67, 706.94, 718.14, 729.47, 740.9, 752.34, 763.74, 775.09, 786.6, 798.1, 809.71, 821.23, 832.89, 844.41, 855.96, 867.69, 879.3, 891.06, 902.84, 914.69, 926.62, 938.31, 949.9, 961.61, 973.31, 985.2, 996.97, 1008.65, 1020.48, 1032.54, 1044.46, 1056.45, 1068.36, 1080.24, 1092.08, 1104.17, 1116.13, 1128.13, 1140.07, 1152.07, 1163.9, 1175.87, 1187.59, 1199.55, 1211.41, 1223.46, 1235.25, 1247.11, 1259.04, 1270.99},
+	{380.67, 393.09, 405.81, 418.81, 432.09, 445.64, 459.47, 473.6, 488.02, 502.67, 517.63, 532.88, 548.39, 564.2, 580.27, 596.67, 613.26, 630.11, 647.2, 664.6, 682.18, 700.11, 718.26, 736.6, 755.13, 773.89, 792.99, 812.21, 831.7, 851.48, 871.36, 891.53, 911.8, 932.28, 953.02, 973.88, 994.9, 1016.12, 1037.43, 1058.9, 1080.61, 1102.5, 1124.44, 1146.69, 1168.82, 1191.14, 1213.76, 1236.63, 1259.23, 1282.03, 1304.95, 1328.14, 1351.31, 1374.49, 1397.97, 1421.56, 1445.16, 1468.82, 1492.47, 1516.26, 1540.03, 1563.84, 1587.59, 1611.75, 1635.7, 1659.64, 1683.88, 1708.31, 1732.58, 1757.15, 1781.52, 1805.86, 1830.43, 1854.89, 1879.46, 1904.09, 1928.73, 1953.39, 1978.05, 2002.7, 2027.5, 2052.3, 2077, 2101.94, 2126.55, 2151.23, 2176.15, 2201.02, 2225.74, 2250.6, 2275.35, 2300.25, 2324.99, 2350.11, 2375.17, 2400.16, 2424.82, 2449.61, 2474.67, 2499.35, 2524.64, 2549.51},
+	{762.65, 788.05, 814.02, 840.57, 867.71, 895.48, 923.79, 952.74, 982.17, 1012.25, 1042.9, 1074.1, 1105.85, 1138.13, 1171.03, 1204.55, 1238.64, 1273.25, 1308.42, 1343.96, 1380.14, 1416.7, 1453.91, 1491.57, 1529.57, 1568.16, 1607.24, 1646.74, 1686.89, 1727.24, 1768.08, 1809.45, 1851.11, 1893.45, 1935.83, 1978.79, 2022.03, 2065.73, 2109.57, 2153.78, 2198.46, 2243.39, 2288.64, 2334.28, 2379.92, 2425.85, 2472.1, 2518.63, 2565.39, 2612.48, 2659.76, 2707.22, 2754.62, 2802.28, 2850.24, 2898.43, 2946.73, 2995.07, 3043.95, 3092.62, 3141.59, 3190.82, 3239.58, 3288.51, 3337.74, 3387.19, 3436.89, 3486.31, 3536, 3586.07, 3635.85, 3685.58, 3735.58, 3785.76, 3835.73, 3885.92, 3936.31, 3986.79, 4037.36, 4087.88, 4138.08, 4188.18, 4238.91, 4289.36, 4340.14, 4390.45, 4441.32, 4491.83, 4542.41, 4593.08, 4643.93, 4694.63, 4745.77, 4796.6, 4847.65, 4899.07, 4949.88, 5000.47, 5051.64, 5102.14},
+	{1526.04, 1576.79, 1628.75, 1681.89, 1736.18, 1791.62, 1848.21, 1906.05, 1965.06, 2025.26, 2086.5, 2148.9, 2212.51, 2277.32, 2343.26, 2410.19, 2478.27, 2547.48, 2617.78, 2689.07, 2761.57, 2835.15, 2909.47, 2984.9, 3061.29, 3138.62, 3216.9, 3296.19, 3375.94, 3456.85, 3538.68, 3621.11, 3704.54, 3788.81, 3873.9, 3959.53, 4045.75, 4132.88, 4220.85, 4309.86, 4398.79, 4488.6, 4578.85, 4669.65, 4760.74, 4852.66, 4945.11, 5038.6, 5132.06, 5226.24, 5320.36, 5414.87, 5510.16, 5605.92, 5701.87, 5798.47, 5895.12, 5991.9, 6088.74, 6186.02, 6283.41, 6381.36, 6479.76, 6578.25, 6677.07, 6775.97, 6875.43, 6975.19, 7074.14, 7173.07, 7271.9, 7371.88, 7471.95, 7572.4, 7672.69, 7773.28, 7873.88, 7974.57, 8075.32, 8176.43, 8277.54, 8378.44, 8479.97, 8581.55, 8683.77, 8785.28, 8886.69, 8988.41, 9089.58, 9191.62, 9292.89, 9394.99, 9496.5, 9597.98, 9699.6, 9800.84, 9902.64, 10004.7, 10106.1, 10208.49},
+	{3052.89, 3154.5, 3258.4, 3364.61, 3473.25, 3584.1, 3697.45, 3813.25, 3931.23, 4051.67, 4174.52, 4299.48, 4426.78, 4556.4, 4687.98, 4821.61, 4957.92, 5096.18, 5236.28, 5379.19, 5523.56, 5670.22, 5818.75, 5969.7, 6122.4, 6276.82, 6433.06, 6591.46, 6751.41, 6912.65, 7075.58, 7240.7, 7407.48, 7576.52, 7746.16, 7916.89, 8089.68, 8264.27, 8439.77, 8617.13, 8795.24, 8974.77, 9156.4, 9338.1, 9521.14, 9705.2, 9890.29, 10076.54, 10263.86, 10452.63, 10641.26, 10830.21, 11020.83, 11212.55, 11404.21, 11597.12, 11790.03, 11983.62, 12177.87, 12373.18, 12569.53, 12765.68, 12961.74, 13157.47, 13354.31, 13552.52, 13751.2, 13949.56, 14148.31, 14347.23, 14546.83, 14746.43, 14946.31, 15145.75, 15346.61, 15547.81, 15748.23, 15950.1, 16150.3, 16352.26, 16553.96, 16755.49, 16956.86, 17159.41, 17361.08, 17563.85, 17765.91, 17968.57, 18170.94, 18373.82, 18576.47, 18780.07, 18984.49, 19187.43, 19390.65, 19593.65, 19796.83, 20000.72, 20203.09, 20405.42},
+	{6107.07, 6310.7, 6519.19, 6732.33, 6950.32, 7172.8, 7399.86, 7631.82, 7868.3, 8110.06, 8356.54, 8607, 8861.85, 9121.64, 9385.73, 9654.37, 9927.27, 10204.89, 10486.5, 10771.45, 11062.01, 11356.23, 11654.15, 11956.11, 12261.92, 12571.97, 12885.44, 13203.37, 13524.53, 13849.52, 14177.31, 14509.76, 14844.05, 15182.18, 15522.31, 15866.69, 16213.96, 16562.75, 16914.35, 17268.64, 17626.19, 17985.94, 18348.59, 18713.61, 19079.52, 19447.07, 19816.98, 20188.71, 20564.21, 20941.4, 21319.79, 21699.56, 22079.32, 22463.1, 22848.26, 23235.6, 23622.72, 24010.62, 24400.2, 24790.52, 25183.2, 25576.48, 25971.05, 26364.82, 26757.16, 27152.94, 27549.76, 27946.7, 28344.43, 28741.53, 29139.88, 29542.36, 29943.74, 30347.34, 30750.26, 31153.84, 31556.96, 31961.23, 32365.13, 32768.97, 33171.05, 33575.69, 33982.8, 34387.25, 34794.57, 35202.05, 35608.53, 36013.24, 36421.22, 36827.04, 37234.73, 37644.96, 38052.53, 38458.23, 38868.28, 39275.69, 39683.11, 40088.05, 40494.57, 40905.23},
+	{12215.55, 12623.36, 13040.69, 13467.38, 13903.83, 14349.48, 14804.6, 15269.23, 15742.59, 16225.4, 16717.88, 17218.96, 17730.45, 18250, 18779.69, 19316.35, 19862.82, 20418.6, 20982.8, 21555.53, 22135.92, 22724.99, 23322.28, 23928.76, 24542.65, 25165.04, 25793.97, 26427.44, 27068.4, 27720.24, 28376.32, 29038.1, 29707.82, 30385.92, 31066.4, 31755.78, 32448.33, 33150.76, 33859.73, 34571.03, 35285.53, 36005.73, 36731.3, 37462.41, 38197.35, 38933.99, 39678.34, 40425.97, 41178.1, 41932.88, 42690.62, 43450.92, 44212.84, 44984.22, 45754.6, 46527.95, 47302.86, 48082.78, 48864.63, 49647.53, 50432.75, 51222.06, 52009.15, 52800.13, 53595.92, 54392.83, 55189.81, 55982.98, 56782.95, 57582.76, 58385.55, 59193.82, 59997.45, 60800.11, 61603.72, 62413.8, 63217.45, 64026.99, 64836.46, 65644.65, 66454.37, 67269.87, 68076.12, 68888.42, 69698.29, 70512.69, 71326.78, 72132.66, 72947.78, 73762.51, 74578.29, 75390.32, 76207.86, 77024.76, 77840.53, 78657.91, 79473.95, 80286.64, 81106.17, 81923.79},
+	{24432, 25247.75, 26082.2, 26936.17, 27808.62, 28700.01, 29610.75, 30538.54, 31486.55, 32453.48, 33438.95, 34441.16, 35463.74, 36503.84, 37563.37, 38639.92, 39736.39, 40850.4, 41979.7, 43126.21, 44288.33, 45467.08, 46661.49, 47874.25, 49101.46, 50345.12, 51602.26, 52874.36, 54162.31, 55463.34, 56778.24, 58106.35, 59447.14, 60802.58, 62169.44, 63546.16, 64934.71, 66339.24, 67751.64, 69171.53, 70605.72, 72044.42, 73497.63, 74955.02, 76422.81, 77898.23, 79384.31, 80877.33, 82377.41, 83886.9, 85403.43, 86931.19, 88467.37, 90007.48, 91549.21, 93100.79, 94651.13, 96204.77, 97761.1, 99327.34, 100894.87, 102463.55, 104043.29, 105617.46, 107205.34, 108798.46, 110383.49, 111977.44, 113577.3, 115183.4, 116785.65, 118382.64, 119990.63, 121593.86, 123206.44, 124827.13, 126435.92, 128053.14, 129676.81, 131297.75, 132918.9, 134546.64, 136171.88, 137782.84, 139407.48, 141034.73, 142662.4, 144289, 145918.98, 147538.11, 149164.62, 150793.8, 152428.81, 154062.94, 155692.06, 157331.17, 158968.48, 160608.9, 162228.23, 163863.6},
+	{48864.14, 50494.81, 52163.29, 53869.43, 55615.52, 57399.53, 59220.89, 61080.7, 62976.75, 64909.37, 66880.65, 68886.77, 70932.39, 73010.3, 75130.67, 77284.09, 79469.31, 81691.4, 83951.23, 86242.92, 88564.51, 90922.25, 93313.26, 95731.99, 98184.14, 100671.23, 103190.33, 105742.26, 108314.43, 110921.49, 113546.09, 116205.12, 118892.27, 121596.54, 124329.21, 127075.87, 129853.68, 132657.62, 135481.2, 138322.4, 141182.27, 144061.44, 146958.28, 149876.42, 152807.42, 155759.67, 158722.1, 161695.9, 164712.88, 167719.81, 170754.06, 173807.42, 176876.94, 179929.45, 183006.27, 186091.38, 189198.16, 192307.55, 195433.44, 198549.27, 201679.88, 204828.3, 207972.12, 211129.98, 214301.97, 217469.66, 220655.36, 223835.84, 227035.19, 230246.94, 233471.81, 236667.48, 239881.3, 243092.84, 246309.7, 249520.98, 252751.05, 255982.1, 259216.38, 262453.56, 265679.3, 268920.34, 272169.47, 275419.34, 278665.34, 281917.47, 285157.12, 288415.62, 291686.12, 294964.66, 298248.2, 301509.7, 304775.25, 308059.03, 311303.2, 314566.44, 317853.25, 321109, 324379.88, 327630.5},
+	{97729.89, 100993.22, 104336.37, 107750.45, 111240.12, 114804.94, 118441.46, 122156.85, 125948.64, 129815.98, 133756.94, 137770.45, 141858.12, 146022.55, 150251.83, 154561.62, 158938.8, 163383.31, 167903.27, 172488.6, 177136.58, 181842.38, 186628.47, 191483.27, 196398.62, 201358.16, 206395.8, 211471.55, 216615.9, 221806.08, 227049.69, 232346.34, 237698.27, 243117.05, 248588.1, 254084.27, 259640.67, 265229.38, 270892.38, 276581, 282295.78, 288055.22, 293876.25, 299744.88, 305638.4, 311577.12, 317525.66, 323498.53, 329513.25, 335526.62, 341591.03, 347702.12, 353822, 359990.16, 366130.88, 372288.44, 378508.75, 384727.72, 390978.72, 397285.78, 403562.06, 409851.1, 416129.75, 422451.66, 428791.16, 435145.56, 441507.03, 447873.38, 454242.97, 460636.56, 467045.66, 473484.78, 479897.4, 486367.72, 492802.56, 499243.94, 505694.38, 512161.9, 518646.9, 525118.7, 531584.94, 538092.06, 544571.75, 551056, 557574.94, 564026.4, 570560.8, 577050.3, 583563.5, 590027.4, 596572, 603097.25, 609604.4, 616201.6, 622710, 629227.7, 635776.9, 642351.94, 648893.44, 655410.56},
+	{195460.4, 201990.56, 208666.81, 215490.45, 222476.06, 229608.39, 236893.3, 244330.64, 251905.25, 259631.95, 267523.66, 275562.3, 283733.2, 292058.62, 300523, 309137.25, 317887.12, 326767.6, 335817.03, 344985.5, 354283.38, 363736.9, 373350.06, 383047.12, 392870.22, 402798.8, 412877.1, 423026.5, 433339.44, 443754.62, 454270.47, 464873.66, 475585.6, 486415.7, 497306.47, 508324.12, 519428.3, 530667.5, 541957.56, 553334.75, 564796.3, 576317.6, 587903.06, 599573.8, 611334.3, 623188.7, 635099.3, 647013.5, 659017.3, 671102.5, 683219.4, 695411.75, 707675.3, 719923.75, 732349.2, 744742.25, 757244.4, 769675.25, 782175.1, 794654.9, 807188.25, 819768.94, 832364.7, 845026.1, 857692.8, 870506.5, 883227.6, 895980.06, 908777.6, 921569.75, 934344.7, 947171.7, 960018.2, 972866.44, 985711, 998621.44, 1.01158906e+06, 1.0244895e+06, 1.0374879e+06, 1.050396e+06, 1.0633805e+06, 1.0762776e+06, 1.0892895e+06, 1.1022776e+06, 1.1153561e+06, 1.1283562e+06, 1.1413378e+06, 1.1543199e+06, 1.1673301e+06, 1.1803404e+06, 1.1934248e+06, 1.2064564e+06, 1.219466e+06, 1.2325445e+06, 1.2457189e+06, 1.2587951e+06, 1.2719982e+06, 1.2850302e+06, 1.2981019e+06, 1.3111816e+06},
+}
+
+// biasData is the mean bias of the raw estimate in rawEstimateData.
+var biasData = [...][]float64{
+	{10.24, 9.72, 9.22, 8.74, 8.27, 7.82, 7.38, 6.96, 6.55, 6.16, 5.79, 5.43, 5.08, 4.75, 4.42, 4.13, 3.85, 3.58, 3.32, 3.08, 2.85, 2.64, 2.44, 2.25, 2.08, 1.91, 1.76, 1.61, 1.46, 1.33, 1.19, 1.07, 0.97, 0.85, 0.77, 0.69, 0.63, 0.54, 0.49, 0.43, 0.34, 0.3, 0.26, 0.22, 0.22, 0.17, 0.15, 0.13, 0.14, 0.17, 0.16, 0.11, 0.05, -0.04, -0.06, -0.12, -0.15, -0.12, -0.14, -0.12, -0.17, -0.22, -0.21, -0.18, -0.21, -0.2, -0.17, -0.17, -0.18, -0.17, -0.2, -0.11, -0.15, -0.12, -0.13, -0.17, -0.21, -0.24, -0.23, -0.24},
+	{21.78, 21.26, 20.75, 20.26, 19.76, 19.27, 18.79, 18.32, 17.85, 17.39, 16.95, 16.51, 16.07, 15.64, 15.22, 14.8, 14.4, 14, 13.61, 13.23, 12.86, 12.5, 12.13, 11.78, 11.43, 11.1, 10.77, 10.45, 10.13, 9.83, 9.52, 9.22, 8.93, 8.66, 8.39, 8.13, 7.87, 7.61, 7.36, 7.11, 6.88, 6.65, 6.43, 6.23, 6.01, 5.79, 5.6, 5.43, 5.24, 5.07, 4.89, 4.7, 4.52, 4.37, 4.2, 4.05, 3.89, 3.75, 3.61, 3.46, 3.31, 3.18, 3.06, 2.95, 2.88, 2.77, 2.66, 2.57, 2.48, 2.37, 2.29, 2.21, 2.12, 2.01, 1.92, 1.84, 1.74, 1.68, 1.64, 1.57, 1.5, 1.45, 1.41, 1.35, 1.24, 1.21, 1.19, 1.13, 1.1, 1.02, 1.01, 0.95, 0.89, 0.82, 0.8, 0.8, 0.8, 0.81, 0.76, 0.72, 0.69, 0.67, 0.66, 0.66, 0.62, 0.61, 0.58, 0.58, 0.54, 0.49, 0.42, 0.42, 0.41, 0.38, 0.41, 0.41, 0.41, 0.33, 0.34, 0.34, 0.3, 0.3, 0.29, 0.25, 0.24, 0.2, 0.14, 0.16, 0.22, 0.23, 0.21, 0.27, 0.22, 0.22, 0.24, 0.26, 0.28, 0.29, 0.3, 0.29, 0.31, 0.3, 0.26, 0.25, 0.25, 0.24, 0.22, 0.25, 0.28, 0.3, 0.32, 0.34, 0.35, 0.37, 0.29, 0.3, 0.33, 0.34, 0.34, 0.36},
+	{43.83, 42.3, 40.81, 39.36, 37.93, 36.54, 35.18, 33.86, 32.56, 31.32, 30.08, 28.89, 27.72, 26.6, 25.49, 24.41, 23.36, 22.36, 21.39, 20.46, 19.55, 18.67, 17.82, 17.02, 16.23, 15.48, 14.74, 14.02, 13.35, 12.72, 12.06, 11.43, 10.85, 10.32, 9.81, 9.32, 8.8, 8.31, 7.84, 7.36, 6.98, 6.58, 6.24, 5.89, 5.57, 5.28, 4.95, 4.7, 4.42, 4.18, 3.97, 3.75, 3.55, 3.26, 3.03, 2.85, 2.71, 2.51, 2.32, 2.15, 2.08, 1.86, 1.73, 1.58, 1.47, 1.38, 1.25, 1.13, 1.09, 0.93, 0.93, 0.86, 0.83, 0.78, 0.74, 0.61, 0.66, 0.48, 0.43, 0.33, 0.33, 0.27, 0.26, 0.26, 0.19, 0.09, 0.01, -0.02, -0.13, -0.14, -0.16, -0.26, -0.31, -0.25, -0.3, -0.36, -0.42, -0.41, -0.41, -0.44, -0.45, -0.45, -0.44, -0.47, -0.53, -0.52},
+	{88.46, 85.44, 82.47, 79.57, 76.74, 73.97, 71.27, 68.63, 66.07, 63.59, 61.14, 58.77, 56.45, 54.21, 52.03, 49.9, 47.82, 45.79, 43.85, 41.95, 40.13, 38.36, 36.67, 35, 33.39, 31.87, 30.35, 28.92, 27.53, 26.16, 24.89, 23.64, 22.42, 21.31, 20.2, 19.18, 18.24, 17.31, 16.37, 15.46, 14.64, 13.84, 13.1, 12.37, 11.71, 11.08, 10.44, 9.87, 9.28, 8.65, 8.08, 7.55, 7.04, 6.51, 6.03, 5.57, 5.18, 4.83, 4.42, 4.15, 3.88, 3.45, 3.18, 2.99, 2.91, 2.7, 2.58, 2.34, 2.15, 1.96, 1.9, 1.71, 1.49, 1.36, 1.32, 1.18, 1.09, 1.13, 0.97, 0.76, 0.76, 0.64, 0.59, 0.49, 0.44, 0.33, 0.23, 0.14, 0.05, -0.11, -0.14, -0.3, -0.34, -0.37, -0.48, -0.52, -0.52, -0.56, -0.72, -0.71, -0.66, -0.61, -0.6, -0.66, -0.53, -0.37},
+	{177.7, 171.64, 165.72, 159.92, 154.27, 148.73, 143.33, 138.08, 132.92, 127.91, 123.04, 118.29, 113.66, 109.15, 104.77, 100.51, 96.39, 92.4, 88.53, 84.76, 81.13, 77.67, 74.25, 70.94, 67.73, 64.69, 61.68, 58.82, 56.09, 53.4, 50.82, 48.41, 46.01, 43.69, 41.47, 39.37, 37.44, 35.55, 33.66, 31.84, 30.11, 28.5, 26.89, 25.43, 24.05, 22.68, 21.38, 20.16, 19, 18.01, 16.94, 15.83, 15.02, 14.07, 13.25, 12.35, 11.67, 10.94, 10.14, 9.47, 8.9, 8.34, 7.74, 7.09, 6.6, 6.1, 5.71, 5.23, 4.89, 4.41, 3.96, 3.69, 3.3, 3.06, 2.84, 2.69, 2.62, 2.31, 1.9, 1.61, 1.31, 1.2, 0.97, 0.65, 0.48, 0.54, 0.46, 0.45, 0.36, 0.24, 0.08, 0.17, 0.13, 0.13, 0.07, 0.07, -0.1, -0.13, -0.41, -0.45, -0.59, -0.54, -0.75, -0.89, -0.96, -1.01},
+	{355.67, 343.09, 330.81, 318.81, 307.09, 295.64, 284.47, 273.6, 263.02, 252.67, 242.63, 232.88, 223.39, 214.2, 205.27, 196.67, 188.26, 180.11, 172.2, 164.6, 157.18, 150.11, 143.26, 136.6, 130.13, 123.89, 117.99, 112.21, 106.7, 101.48, 96.36, 91.53, 86.8, 82.28, 78.02, 73.88, 69.9, 66.12, 62.43, 58.9, 55.61, 52.5, 49.44, 46.69, 43.82, 41.14, 38.76, 36.63, 34.23, 32.03, 29.95, 28.14, 26.31, 24.49, 22.97, 21.56, 20.16, 18.82, 17.47, 16.26, 15.03, 13.84, 12.59, 11.75, 10.7, 9.64, 8.88, 8.31, 7.58, 7.15, 6.52, 5.86, 5.43, 4.89, 4.46, 4.09, 3.73, 3.39, 3.05, 2.7, 2.5, 2.3, 2, 1.94, 1.55, 1.23, 1.15, 1.02, 0.74, 0.6, 0.35, 0.25, -0.01, 0.11, 0.17, 0.16, -0.18, -0.39, -0.33, -0.65, -0.36, -0.49},
+	{711.65, 686.05, 661.02, 636.57, 612.71, 589.48, 566.79, 544.74, 523.17, 502.25, 481.9, 462.1, 442.85, 424.13, 406.03, 388.55, 371.64, 355.25, 339.42, 323.96, 309.14, 294.7, 280.91, 267.57, 254.57, 242.16, 230.24, 218.74, 207.89, 197.24, 187.08, 177.45, 168.11, 159.45, 150.83, 142.79, 135.03, 127.73, 120.57, 113.78, 107.46, 101.39, 95.64, 90.28, 84.92, 79.85, 75.1, 70.63, 66.39, 62.48, 58.76, 55.22, 51.62, 48.28, 45.24, 42.43, 39.73, 37.07, 34.95, 32.62, 30.59, 28.82, 26.58, 24.51, 22.74, 21.19, 19.89, 18.31, 17, 16.07, 14.85, 13.58, 12.58, 11.76, 10.73, 9.92, 9.31, 8.79, 8.36, 7.88, 7.08, 6.18, 5.91, 5.36, 5.14, 4.45, 4.32, 3.83, 3.41, 3.08, 2.93, 2.63, 2.77, 2.6, 2.65, 3.07, 2.88, 2.47, 2.64, 2.14},
+	{1424.04, 1372.79, 1322.75, 1273.89, 1226.18, 1179.62, 1134.21, 1090.05, 1047.06, 1005.26, 964.5, 924.9, 886.51, 849.32, 813.26, 778.19, 744.27, 711.48, 679.78, 649.07, 619.57, 591.15, 563.47, 536.9, 511.29, 486.62, 462.9, 440.19, 417.94, 396.85, 376.68, 357.11, 338.54, 320.81, 303.9, 287.53, 271.75, 256.88, 242.85, 229.86, 216.79, 204.6, 192.85, 181.65, 170.74, 160.66, 151.11, 142.6, 134.06, 126.24, 118.36, 110.87, 104.16, 97.92, 91.87, 86.47, 81.12, 75.9, 70.74, 66.02, 61.41, 57.36, 53.76, 50.25, 47.07, 43.97, 41.43, 39.19, 36.14, 33.07, 29.9, 27.88, 25.95, 24.4, 22.69, 21.28, 19.88, 18.57, 17.32, 16.43, 15.54, 14.44, 13.97, 13.55, 13.77, 13.28, 12.69, 12.41, 11.58, 11.62, 10.89, 10.99, 10.5, 9.98, 9.6, 8.84, 8.64, 8.7, 8.1, 8.49},
+	{2848.89, 2746.5, 2646.4, 2548.61, 2453.25, 2360.1, 2269.45, 2181.25, 2095.23, 2011.67, 1930.52, 1851.48, 1774.78, 1700.4, 1627.98, 1557.61, 1489.92, 1424.18, 1360.28, 1299.19, 1239.56, 1182.22, 1126.75, 1073.7, 1022.4, 972.82, 925.06, 879.46, 835.41, 792.65, 751.58, 712.7, 675.48, 640.52, 606.16, 572.89, 541.68, 512.27, 483.77, 457.13, 431.24, 406.77, 384.4, 362.1, 341.14, 321.2, 302.29, 284.54, 267.86, 252.63, 237.26, 222.21, 208.83, 196.55, 184.21, 173.12, 162.03, 151.62, 141.87, 133.18, 125.53, 117.68, 109.74, 101.47, 94.31, 88.52, 83.2, 77.56, 72.31, 67.23, 62.83, 58.43, 54.31, 49.75, 46.61, 43.81, 40.23, 38.1, 34.3, 32.26, 29.96, 27.49, 24.86, 23.41, 21.08, 19.85, 17.91, 16.57, 14.94, 13.82, 12.47, 12.07, 12.49, 11.43, 10.65, 9.65, 8.83, 8.72, 7.09, 5.42},
+	{5698.07, 5492.7, 5292.19, 5096.33, 4905.32, 4718.8, 4536.86, 4359.82, 4187.3, 4020.06, 3857.54, 3699, 3544.85, 3395.64, 3250.73, 3110.37, 2974.27, 2842.89, 2715.5, 2591.45, 2473.01, 2358.23, 2247.15, 2140.11, 2036.92, 1937.97, 1842.44, 1751.37, 1663.53, 1579.52, 1498.31, 1421.76, 1347.05, 1276.18, 1207.31, 1142.69, 1080.96, 1020.75, 963.35, 908.64, 857.19, 807.94, 761.59, 717.61, 674.52, 633.07, 593.98, 556.71, 523.21, 491.4, 460.79, 431.56, 402.32, 377.1, 353.26, 331.6, 309.72, 288.62, 269.2, 250.52, 234.2, 218.48, 204.05, 188.82, 172.16, 158.94, 146.76, 134.7, 123.43, 111.53, 100.88, 94.36, 86.74, 81.34, 75.26, 69.84, 63.96, 59.23, 54.13, 48.97, 42.05, 37.69, 35.8, 31.25, 29.57, 28.05, 25.53, 21.24, 20.22, 17.04, 15.73, 16.96, 15.53, 12.23, 13.28, 11.69, 10.11, 6.05, 3.57, 5.23},
+	{11396.55, 10985.36, 10583.69, 10191.38, 9808.83, 9435.48, 9071.6, 8717.23, 8371.59, 8035.4, 7708.88, 7390.96, 7083.45, 6784, 6494.69, 6212.35, 5939.82, 5676.6, 5421.8, 5175.53, 4936.92, 4706.99, 4485.28, 4272.76, 4067.65, 3871.04, 3680.97, 3495.44, 3317.4, 3150.24, 2987.32, 2830.1, 2680.82, 2539.92, 2401.4, 2271.78, 2145.33, 2028.76, 1918.73, 1811.03, 1706.53, 1607.73, 1514.3, 1426.41, 1342.35, 1259.99, 1185.34, 1113.97, 1047.1, 982.88, 921.62, 862.92, 805.84, 758.22, 709.6, 663.95, 619.86, 580.78, 543.63, 507.53, 473.75, 444.06, 412.15, 384.13, 360.92, 338.83, 316.81, 290.98, 271.95, 252.76, 236.55, 225.82, 210.45, 194.11, 178.72, 169.8, 154.45, 144.99, 135.46, 124.65, 115.37, 111.87, 99.12, 92.42, 83.29, 78.69, 73.78, 60.66, 56.78, 52.51, 49.29, 42.32, 40.86, 38.76, 35.53, 33.91, 30.95, 24.64, 25.17, 23.79},
+	{22794, 21971.75, 21168.2, 20384.17, 19618.62, 18872.01, 18144.75, 17434.54, 16744.55, 16073.48, 15420.95, 14785.16, 14169.74, 13571.84, 12993.37, 12431.92, 11890.39, 11366.4, 10857.7, 10366.21, 9890.33, 9431.08, 8987.49, 8562.25, 8151.46, 7757.12, 7376.26, 7010.36, 6660.31, 6323.34, 6000.24, 5690.35, 5393.14, 5110.58, 4839.44, 4578.16, 4328.71, 4095.24, 3869.64, 3651.53, 3447.72, 3248.42, 3063.63, 2883.02, 2712.81, 2550.23, 2398.31, 2253.33, 2115.41, 1986.9, 1865.43, 1755.19, 1653.37, 1555.48, 1459.21, 1372.79, 1285.13, 1200.77, 1119.1, 1047.34, 976.87, 907.55, 849.29, 785.46, 735.34, 690.46, 637.49, 593.44, 555.3, 523.4, 487.65, 446.64, 416.63, 381.86, 356.44, 339.13, 309.92, 289.14, 274.81, 257.75, 240.9, 230.64, 217.87, 190.85, 177.49, 166.73, 156.4, 145, 136.99, 118.11, 106.62, 97.8, 94.82, 90.94, 82.06, 83.17, 82.48, 84.9, 66.23, 63.59},
+	{45588.14, 43942.81, 42335.29, 40765.43, 39235.52, 37743.53, 36288.89, 34872.7, 33492.75, 32149.37, 30844.65, 29574.77, 28344.39, 27146.3, 25990.67, 24868.09, 23777.31, 22723.4, 21707.23, 20722.92, 19768.51, 18850.25, 17965.26, 17107.99, 16284.14, 15495.23, 14738.33, 14014.26, 13310.43, 12641.49, 11990.09, 11373.12, 10784.27, 10212.54, 9669.21, 9139.87, 8641.68, 8169.62, 7717.21, 7282.41, 6866.27, 6469.44, 6090.28, 5732.42, 5387.42, 5063.67, 4750.09, 4447.9, 4188.87, 3919.82, 3678.06, 3455.42, 3248.93, 3025.46, 2826.27, 2635.38, 2466.15, 2299.55, 2149.43, 1989.26, 1843.87, 1716.3, 1584.12, 1465.99, 1361.97, 1253.65, 1163.36, 1067.85, 991.19, 926.93, 875.81, 795.48, 733.3, 668.84, 609.7, 544.99, 499.04, 454.1, 412.38, 373.56, 323.32, 288.34, 261.47, 235.34, 205.33, 181.47, 145.12, 127.64, 122.14, 124.65, 132.19, 117.7, 107.26, 115.04, 83.19, 70.45, 81.25, 61, 55.89, 30.5},
+	{91176.89, 87887.22, 84677.37, 81538.45, 78475.12, 75486.94, 72570.46, 69732.85, 66971.64, 64285.98, 61673.93, 59134.46, 56669.12, 54280.55, 51956.83, 49713.63, 47537.8, 45429.31, 43396.26, 41428.6, 39523.58, 37676.38, 35909.47, 34211.26, 32573.63, 30980.15, 29464.79, 27987.54, 26578.91, 25216.08, 23906.69, 22650.34, 21449.27, 20315.04, 19233.1, 18176.27, 17179.67, 16215.37, 15325.36, 14461, 13622.77, 12829.21, 12097.25, 11412.86, 10753.4, 10139.11, 9534.67, 8954.52, 8416.24, 7876.61, 7388.02, 6946.12, 6513, 6128.16, 5715.89, 5320.43, 4987.74, 4653.71, 4351.73, 4105.79, 3829.06, 3565.08, 3290.75, 3059.65, 2846.16, 2647.56, 2456.04, 2269.37, 2085.97, 1926.55, 1782.67, 1668.77, 1528.4, 1445.73, 1327.55, 1215.93, 1113.38, 1027.9, 959.91, 878.69, 791.91, 746.09, 672.76, 603.99, 569.96, 468.4, 449.8, 386.31, 346.47, 257.39, 248.98, 221.26, 175.4, 219.61, 175.01, 139.67, 135.88, 157.96, 146.41, 110.55},
+	{182353.4, 175776.56, 169345.81, 163062.45, 156941.06, 150966.39, 145144.3, 139474.64, 133942.25, 128561.95, 123346.65, 118278.3, 113342.19, 108560.61, 103918.01, 99425.26, 95068.12, 90841.59, 86784.04, 82845.51, 79036.38, 75382.91, 71889.06, 68479.14, 65195.23, 62016.81, 58988.09, 56030.49, 53236.43, 50544.61, 47953.47, 45449.67, 43054.58, 40777.69, 38561.48, 36472.14, 34469.31, 32601.47, 30784.56, 29054.73, 27409.31, 25823.64, 24302.05, 22865.83, 21519.3, 20266.67, 19070.32, 17877.52, 16774.34, 15752.53, 14762.36, 13847.75, 13004.34, 12145.74, 11464.18, 10750.26, 10145.4, 9469.27, 8862.13, 8234.89, 7661.24, 7134.93, 6623.68, 6178.13, 5737.83, 5444.51, 5058.63, 4704.07, 4394.63, 4079.72, 3747.69, 3467.71, 3207.2, 2948.43, 2685.97, 2489.44, 2350.05, 2143.47, 2034.88, 1835.95, 1713.56, 1503.6, 1408.44, 1289.62, 1261.15, 1154.19, 1028.8, 903.83, 807.09, 710.41, 687.7, 612.32, 514.96, 486.48, 553.82, 523.09, 619.2, 544.21, 508.87, 481.57},
+}
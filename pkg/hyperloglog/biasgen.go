@@ -0,0 +1,122 @@
+// +build ignore
+
+// biasgen generates the empirical bias correction tables of HyperLogLogPlus,
+// the mean raw estimate and bias are measured by simulating random 64-bit hashes
+// for the cardinalities up to 5m with each precision.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"math"
+	"math/bits"
+)
+
+const (
+	minPrecision = 4
+	maxPrecision = 18
+	points       = 100     // max number of cardinalities per precision
+	budget       = 1 << 26 // number of hashes per precision
+)
+
+func main() {
+	var raw, bias bytes.Buffer
+	for p := uint(minPrecision); p <= maxPrecision; p++ {
+		estimates, biases := simulate(p)
+		raw.WriteString("\t{")
+		bias.WriteString("\t{")
+		for i := range estimates {
+			fmt.Fprintf(&raw, "%s, ", round2(estimates[i]))
+			fmt.Fprintf(&bias, "%s, ", round2(biases[i]))
+		}
+		raw.WriteString("},\n")
+		bias.WriteString("},\n")
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by biasgen.go; DO NOT EDIT.\n\n")
+	out.WriteString("package hyperloglog\n\n")
+	out.WriteString("// rawEstimateData is the mean raw estimate at the sampled cardinalities for precision 4 to 18.\n")
+	out.WriteString("var rawEstimateData = [...][]float64{\n" + raw.String() + "}\n\n")
+	out.WriteString("// biasData is the mean bias of the raw estimate in rawEstimateData.\n")
+	out.WriteString("var biasData = [...][]float64{\n" + bias.String() + "}\n")
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("bias.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func round2(v float64) string {
+	return fmt.Sprint(float32(math.Round(v*100) / 100))
+}
+
+func simulate(p uint) (estimates, biases []float64) {
+	m := 1 << p
+	max := 5 * m
+	step := 1
+	if max > points {
+		step = max / points
+	}
+	runs := budget / max
+	if runs > 10000 {
+		runs = 10000
+	}
+
+	n := max / step
+	estimates = make([]float64, n)
+	biases = make([]float64, n)
+	alpha := 0.7213 / (1 + 1.079/float64(m))
+	switch m {
+	case 16:
+		alpha = 0.673
+	case 32:
+		alpha = 0.697
+	case 64:
+		alpha = 0.709
+	}
+
+	rnd := uint64(p)*0x9e3779b97f4a7c15 + 1
+	registers := make([]uint8, m)
+	for r := 0; r < runs; r++ {
+		for i := range registers {
+			registers[i] = 0
+		}
+		sum := float64(m)
+		for c := 1; c <= n*step; c++ {
+			rnd += 0x9e3779b97f4a7c15
+			x := mix(rnd)
+			j := x >> (64 - p)
+			rank := uint8(bits.LeadingZeros64(x<<p|1<<(p-1))) + 1
+			if rank > registers[j] {
+				sum += math.Ldexp(1, -int(rank)) - math.Ldexp(1, -int(registers[j]))
+				registers[j] = rank
+			}
+			if c%step == 0 {
+				e := alpha * float64(m) * float64(m) / sum
+				estimates[c/step-1] += e
+				biases[c/step-1] += e - float64(c)
+			}
+		}
+	}
+	for i := range estimates {
+		estimates[i] /= float64(runs)
+		biases[i] /= float64(runs)
+	}
+	return
+}
+
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -1,4 +1,4 @@
-// Package hyperloglog implements HyperLogLog cardinality estimation.
+// Package hyperloglog implements HyperLogLog and HyperLogLog++ cardinality estimation.
 package hyperloglog
 
 import (
@@ -0,0 +1,277 @@
+package hyperloglog
+
+/*
+   https://research.google.com/pubs/archive/40671.pdf
+*/
+
+//go:generate go run biasgen.go
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// HyperLogLogPlus is the HyperLogLog++ for cardinality estimation,
+// it uses 64-bit hashes so that it does not saturate for large cardinalities,
+// the registers are kept in sparse representation with precision 25 for small cardinalities
+// and converted to dense representation when it grows,
+// the raw estimate is corrected by the empirical bias for cardinalities up to 5m.
+// HyperLogLogPlus is not safe for concurrent use, Add and Merge change it,
+// while Count and merging it into another sketch only read it.
+type HyperLogLogPlus struct {
+	registers []uint8  // dense registers, nil in sparse representation
+	sparse    []uint32 // sorted sparse entries, index<<6 | rank at sparsePrecision
+	tmp       []uint32 // unsorted sparse entries to be merged into sparse
+	m         uint32   // number of registers
+	p         uint8    // precision, number of bits to find registers bucket number
+	alpha     float64  // bias-correction constant
+}
+
+const (
+	minPrecision    = 4
+	maxPrecision    = 18
+	sparsePrecision = 25
+	sparseRankBits  = 6
+	biasNeighbors   = 6 // number of nearest neighbors to interpolate the bias
+)
+
+// thresholds is the cardinality below which linear counting is used for precision 4 to 18.
+var thresholds = [...]float64{
+	10, 20, 40, 80, 220, 400, 900, 1800, 3100,
+	6500, 11500, 20000, 50000, 120000, 350000,
+}
+
+// NewPlus creates a new HyperLogLogPlus with the given `precision`,
+// which is from 4 to 18 for 2^precision registers bucket.
+func NewPlus(precision uint8) (*HyperLogLogPlus, error) {
+	if precision < minPrecision || precision > maxPrecision {
+		return nil, errors.New("precision must be in range [4, 18]")
+	}
+
+	m := uint32(1) << precision
+	return &HyperLogLogPlus{
+		m:     m,
+		p:     precision,
+		alpha: calculateAlpha(uint(m)),
+	}, nil
+}
+
+// NewPlusGuess creates a new HyperLogLogPlus within the given standard error.
+func NewPlusGuess(stdErr float64) (*HyperLogLogPlus, error) {
+	m := math.Pow(1.04/stdErr, 2)
+	return NewPlus(uint8(math.Max(minPrecision, math.Ceil(math.Log2(m)))))
+}
+
+// Add adds the data to the set.
+func (h *HyperLogLogPlus) Add(data []byte) {
+	x := hash64(data)
+	if h.registers != nil {
+		j, r := h.indexRank(x)
+		if r > h.registers[j] {
+			h.registers[j] = r
+		}
+		return
+	}
+
+	idx := uint32(x >> (64 - sparsePrecision))
+	r := uint32(bits.LeadingZeros64(x<<sparsePrecision|1<<(sparsePrecision-1))) + 1
+	h.tmp = append(h.tmp, idx<<sparseRankBits|r)
+	if uint32(len(h.tmp))*16 >= h.m {
+		h.mergeSparse()
+	}
+}
+
+// Count returns the estimated cardinality of the set.
+// The pending sparse entries are counted from a copy, Count does not change the HyperLogLogPlus.
+func (h *HyperLogLogPlus) Count() uint64 {
+	registers := h.registers
+	if registers == nil {
+		entries := h.entries()
+		// a sparse entry takes 4 bytes and a dense register takes 1 byte.
+		if uint32(len(entries))*4 <= h.m {
+			// linear counting with sparse precision.
+			m := float64(uint32(1) << sparsePrecision)
+			return uint64(math.Round(m * math.Log(m/(m-float64(len(entries))))))
+		}
+		registers = make([]uint8, h.m)
+		for _, k := range entries {
+			h.addSparseEntry(registers, k)
+		}
+	}
+
+	sum, zeros, m := 0.0, 0, float64(h.m)
+	for _, r := range registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := h.alpha * m * m / sum
+	if estimate <= 5*m {
+		estimate -= h.bias(estimate)
+	}
+
+	if zeros > 0 {
+		if lc := m * math.Log(m/float64(zeros)); lc <= thresholds[h.p-minPrecision] {
+			return uint64(math.Round(lc))
+		}
+	}
+	return uint64(math.Round(math.Max(estimate, 0)))
+}
+
+// Merge combines the HyperLogLogPlus with the other, the other is not changed.
+func (h *HyperLogLogPlus) Merge(other *HyperLogLogPlus) error {
+	if h.p != other.p {
+		return errors.New("precision must match")
+	}
+
+	if h.registers == nil && other.registers == nil {
+		h.tmp = append(append(h.tmp, other.sparse...), other.tmp...)
+		h.mergeSparse()
+		return nil
+	}
+
+	h.toDense()
+	if other.registers == nil {
+		for _, k := range other.sparse {
+			h.addSparseEntry(h.registers, k)
+		}
+		for _, k := range other.tmp {
+			h.addSparseEntry(h.registers, k)
+		}
+		return nil
+	}
+	for j, r := range other.registers {
+		if r > h.registers[j] {
+			h.registers[j] = r
+		}
+	}
+	return nil
+}
+
+// Reset restores the HyperLogLogPlus to its original state.
+func (h *HyperLogLogPlus) Reset() {
+	h.registers, h.sparse, h.tmp = nil, nil, nil
+}
+
+// Precision returns the precision.
+func (h *HyperLogLogPlus) Precision() uint8 {
+	return h.p
+}
+
+// indexRank returns the register index and the rank of the 64-bit hash x with precision p.
+func (h *HyperLogLogPlus) indexRank(x uint64) (uint32, uint8) {
+	return uint32(x >> (64 - h.p)), uint8(bits.LeadingZeros64(x<<h.p|1<<(h.p-1))) + 1
+}
+
+// mergeSparse merges tmp into sparse keeping the max rank for each index,
+// and converts to dense representation if sparse is larger than the dense registers.
+func (h *HyperLogLogPlus) mergeSparse() {
+	if len(h.tmp) == 0 {
+		return
+	}
+
+	h.sparse = sortSparse(append(h.sparse, h.tmp...))
+	h.tmp = h.tmp[:0]
+
+	// a sparse entry takes 4 bytes and a dense register takes 1 byte.
+	if uint32(len(h.sparse))*4 > h.m {
+		h.toDense()
+	}
+}
+
+// entries returns the sorted sparse entries with the pending ones,
+// the pending entries are merged into a copy so that the sparse entries are not changed.
+func (h *HyperLogLogPlus) entries() []uint32 {
+	if len(h.tmp) == 0 {
+		return h.sparse
+	}
+	entries := make([]uint32, 0, len(h.sparse)+len(h.tmp))
+	return sortSparse(append(append(entries, h.sparse...), h.tmp...))
+}
+
+// sortSparse sorts the sparse entries in place and removes the entries of the same index but the highest rank.
+func sortSparse(entries []uint32) []uint32 {
+	sort.Slice(entries, func(i, j int) bool { return entries[i] < entries[j] })
+	n := 0
+	for i, k := range entries {
+		// the entries of the same index are sorted by rank, keep the last.
+		if i+1 < len(entries) && entries[i+1]>>sparseRankBits == k>>sparseRankBits {
+			continue
+		}
+		entries[n] = k
+		n++
+	}
+	return entries[:n]
+}
+
+func (h *HyperLogLogPlus) toDense() {
+	if h.registers != nil {
+		return
+	}
+	h.registers = make([]uint8, h.m)
+	for _, k := range h.sparse {
+		h.addSparseEntry(h.registers, k)
+	}
+	for _, k := range h.tmp {
+		h.addSparseEntry(h.registers, k)
+	}
+	h.sparse, h.tmp = nil, nil
+}
+
+// addSparseEntry updates the dense registers with the sparse entry k,
+// the rank at precision p is found in the index bits below p if any is set,
+// otherwise it's the rank at sparse precision plus the number of those bits.
+func (h *HyperLogLogPlus) addSparseEntry(registers []uint8, k uint32) {
+	idx, r := k>>sparseRankBits, uint8(k&(1<<sparseRankBits-1))
+	extra := uint(sparsePrecision - h.p)
+	if low := idx & (1<<extra - 1); low != 0 {
+		r = uint8(bits.LeadingZeros32(low<<(32-extra))) + 1
+	} else {
+		r += uint8(extra)
+	}
+
+	if j := idx >> extra; r > registers[j] {
+		registers[j] = r
+	}
+}
+
+// bias interpolates the bias of the raw estimate with the nearest neighbors in biasData.
+func (h *HyperLogLogPlus) bias(estimate float64) float64 {
+	raw, bias := rawEstimateData[h.p-minPrecision], biasData[h.p-minPrecision]
+	right := sort.SearchFloat64s(raw, estimate)
+	left := right - 1
+	sum := 0.0
+	for n := 0; n < biasNeighbors; n++ {
+		if right >= len(raw) || (left >= 0 && estimate-raw[left] < raw[right]-estimate) {
+			sum += bias[left]
+			left--
+		} else {
+			sum += bias[right]
+			right++
+		}
+	}
+	return sum / biasNeighbors
+}
+
+// hash64 is FNV-1a 64 finalized by SplitMix64.
+func hash64(data []byte) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	x := uint64(offset64)
+	for _, c := range data {
+		x ^= uint64(c)
+		x *= prime64
+	}
+
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,160 @@
+package hyperloglog
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func addRange(h *HyperLogLogPlus, from, to uint64) {
+	data := make([]byte, 8)
+	for i := from; i < to; i++ {
+		binary.BigEndian.PutUint64(data, i)
+		h.Add(data)
+	}
+}
+
+func TestPlusCardinality(t *testing.T) {
+	for _, p := range []uint8{4, 10, 14, 18} {
+		h, err := NewPlus(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 4 standard errors of the estimate.
+		maxErr := 4 * 1.04 / math.Sqrt(float64(uint64(1)<<p))
+
+		var added uint64
+		for _, n := range []uint64{10, 100, 1000, 10000, 100000, 1000000} {
+			addRange(h, added, n)
+			added = n
+			c := h.Count()
+			stdErr := calculateStdErr(n, c)
+			t.Logf("p=%d n=%d estimate=%d stdErr=%.4f sparse=%v", p, n, c, stdErr, h.registers == nil)
+			if stdErr > maxErr {
+				t.Errorf("p=%d n=%d: expected stdErr at most %v, got %v", p, n, maxErr, stdErr)
+			}
+		}
+		if h.registers == nil {
+			t.Errorf("p=%d: expected dense representation", p)
+		}
+	}
+}
+
+func TestPlusWords(t *testing.T) {
+	h, _ := NewPlusGuess(0.01)
+	for _, w := range words {
+		h.Add([]byte(w))
+		h.Add([]byte(w))
+	}
+	w, c := uint64(len(words)), h.Count()
+	if stdErr := calculateStdErr(w, c); stdErr > 0.03 {
+		t.Errorf("Word list is %v words, estimate is %v, stdErr %v", w, c, stdErr)
+	}
+	if h.Precision() != 14 || h.registers != nil {
+		t.Errorf("expected sparse representation with precision 14, got %d", h.Precision())
+	}
+}
+
+func TestPlusMerge(t *testing.T) {
+	sparse1, _ := NewPlus(14)
+	sparse2, _ := NewPlus(14)
+	dense, _ := NewPlus(14)
+	addRange(sparse1, 0, 1000)
+	addRange(sparse2, 500, 1500)
+	addRange(dense, 1000, 100000)
+
+	if err := sparse1.Merge(sparse2); err != nil {
+		t.Fatal(err)
+	}
+	if c := sparse1.Count(); sparse1.registers != nil || calculateStdErr(1500, c) > 0.02 {
+		t.Errorf("expected sparse estimate about 1500, got %d", c)
+	}
+
+	// sparse into dense and dense into sparse give the same registers.
+	a, _ := NewPlus(14)
+	a.Merge(dense)
+	a.Merge(sparse1)
+	if err := sparse1.Merge(dense); err != nil {
+		t.Fatal(err)
+	}
+	for j := range a.registers {
+		if a.registers[j] != sparse1.registers[j] {
+			t.Fatalf("register %d: expected %d, got %d", j, a.registers[j], sparse1.registers[j])
+		}
+	}
+	if c := sparse1.Count(); calculateStdErr(100000, c) > 0.04 {
+		t.Errorf("expected estimate about 100000, got %d", c)
+	}
+
+	other, _ := NewPlus(12)
+	if err := sparse1.Merge(other); err == nil {
+		t.Error("expected error for precision mismatch")
+	}
+
+	sparse1.Reset()
+	if c := sparse1.Count(); c != 0 || sparse1.registers != nil {
+		t.Errorf("expected empty sparse representation, got %d", c)
+	}
+}
+
+func TestPlusUnchanged(t *testing.T) {
+	// Count does not change the sketch and Merge does not change the other.
+	for _, n := range []uint64{100, 2000} {
+		h, _ := NewPlus(14)
+		addRange(h, 0, n)
+		sparse := append([]uint32(nil), h.sparse...)
+		tmp := append([]uint32(nil), h.tmp...)
+
+		c := h.Count()
+		target, _ := NewPlus(14)
+		if err := target.Merge(h); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(sparse, append([]uint32(nil), h.sparse...)) ||
+			!reflect.DeepEqual(tmp, append([]uint32(nil), h.tmp...)) || h.registers != nil {
+			t.Errorf("n=%d: expected the sketch unchanged", n)
+		}
+		if tc := target.Count(); tc != c {
+			t.Errorf("n=%d: expected %d, got %d", n, c, tc)
+		}
+	}
+}
+
+func TestPlusSparseToDense(t *testing.T) {
+	// the registers converted from sparse are the same as the ones added in dense representation.
+	sparse, _ := NewPlus(10)
+	dense, _ := NewPlus(10)
+	dense.toDense()
+	addRange(sparse, 0, 200)
+	addRange(dense, 0, 200)
+	sparse.toDense()
+	for j := range dense.registers {
+		if sparse.registers[j] != dense.registers[j] {
+			t.Fatalf("register %d: expected %d, got %d", j, dense.registers[j], sparse.registers[j])
+		}
+	}
+}
+
+func TestPlusPrecision(t *testing.T) {
+	for _, p := range []uint8{0, 3, 19} {
+		if _, err := NewPlus(p); err == nil {
+			t.Errorf("expected error for precision %d", p)
+		}
+	}
+	for i, raw := range rawEstimateData {
+		if !sort.Float64sAreSorted(raw) || len(raw) != len(biasData[i]) {
+			t.Errorf("precision %d: expected sorted bias data", i+minPrecision)
+		}
+	}
+}
+
+func BenchmarkPlusAdd(b *testing.B) {
+	h, _ := NewPlus(14)
+	data := make([]byte, 8)
+	for i := 0; i < b.N; i++ {
+		binary.BigEndian.PutUint64(data, uint64(i))
+		h.Add(data)
+	}
+}
@@ -4,6 +4,7 @@ package jumphash
 import (
 	"hash/crc64"
 	"io"
+	"strconv"
 )
 
 var (
@@ -61,3 +62,8 @@ func (h *Hasher) N() int {
 func (h *Hasher) Hash(key string) int {
 	return HashString(key, int(h.n))
 }
+
+// Get returns the bucket for the given key in decimal string.
+func (h *Hasher) Get(key string) (string, error) {
+	return strconv.Itoa(h.Hash(key)), nil
+}
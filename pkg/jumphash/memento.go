@@ -0,0 +1,172 @@
+package jumphash
+
+/*
+   https://arxiv.org/abs/2306.09783
+*/
+
+import (
+	"errors"
+	"hash/crc64"
+	"sync"
+)
+
+type (
+	// Memento is a jump consistent hash over named nodes,
+	// any node can be removed with minimal key movement
+	// by keeping a replacement table for the removed buckets.
+	Memento struct {
+		// names maps bucket to node name, empty for removed bucket.
+		names []string
+		// buckets maps node name to bucket.
+		buckets map[string]int
+		// replaced maps removed bucket to its replacement.
+		replaced map[int]replacement
+		// working is the number of working buckets.
+		working int
+		// lastRemoved is the last removed bucket,
+		// it is len(names) if there is no removed bucket.
+		lastRemoved int
+
+		mu sync.RWMutex
+	}
+
+	replacement struct {
+		// replacer is the number of working buckets after removal.
+		replacer int
+		// prevRemoved is the bucket removed before this one.
+		prevRemoved int
+	}
+)
+
+var (
+	// tableECMA is the 64-bit Cyclic Redundancy Check (CRC-64) table with the ECMA polynomial.
+	tableECMA = crc64.MakeTable(crc64.ECMA)
+	// ErrNoNode when there is no node added.
+	ErrNoNode = errors.New("no node added")
+)
+
+// NewMemento returns a new instance of Memento with the given nodes.
+func NewMemento(nodes ...string) *Memento {
+	m := &Memento{
+		buckets:  map[string]int{},
+		replaced: map[int]replacement{},
+	}
+	m.AddNode(nodes...)
+	return m
+}
+
+// AddNode adds nodes, the bucket removed most recently is reused first.
+func (m *Memento) AddNode(nodes ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, name := range nodes {
+		if _, ok := m.buckets[name]; ok {
+			continue
+		}
+
+		b := m.lastRemoved
+		if r, ok := m.replaced[b]; ok {
+			m.lastRemoved = r.prevRemoved
+			delete(m.replaced, b)
+			m.names[b] = name
+		} else {
+			m.names = append(m.names, name)
+			m.lastRemoved = len(m.names)
+		}
+		m.buckets[name] = b
+		m.working++
+	}
+}
+
+// RemoveNode deletes node, only the keys on the removed node are moved.
+func (m *Memento) RemoveNode(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, ok := m.buckets[name]
+	if !ok {
+		return false
+	}
+
+	m.working--
+	delete(m.buckets, name)
+	m.names[b] = ""
+
+	if len(m.replaced) == 0 && b == len(m.names)-1 {
+		// shrink from the end as plain jump hash does.
+		m.names = m.names[:b]
+		m.lastRemoved = b
+		return true
+	}
+
+	m.replaced[b] = replacement{
+		replacer:    m.working,
+		prevRemoved: m.lastRemoved,
+	}
+	m.lastRemoved = b
+	return true
+}
+
+// Get returns the node for the given key.
+func (m *Memento) Get(key string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.working == 0 {
+		return "", ErrNoNode
+	}
+
+	k := crc64.Checksum([]byte(key), tableECMA)
+	return m.names[m.bucket(k)], nil
+}
+
+// Nodes returns the list of nodes ordered by bucket.
+func (m *Memento) Nodes() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	nodes := make([]string, 0, m.working)
+	for _, name := range m.names {
+		if name != "" {
+			nodes = append(nodes, name)
+		}
+	}
+	return nodes
+}
+
+// Len returns the number of nodes.
+func (m *Memento) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.working
+}
+
+func (m *Memento) bucket(key uint64) int {
+	b := Hash(key, len(m.names))
+
+	r, ok := m.replaced[b]
+	for ok {
+		// rehash into the working buckets at the time b was removed.
+		h := int(rehash(key, b) % uint64(r.replacer))
+		// h was removed before b, follow its replacer.
+		for rh, ok := m.replaced[h]; ok && rh.replacer >= r.replacer; rh, ok = m.replaced[h] {
+			h = rh.replacer
+		}
+		b = h
+		r, ok = m.replaced[b]
+	}
+	return b
+}
+
+// rehash mixes key with bucket using the finalizer of SplitMix64.
+func rehash(key uint64, bucket int) uint64 {
+	x := key + uint64(bucket+1)*0x9e3779b97f4a7c15
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
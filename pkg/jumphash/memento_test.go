@@ -0,0 +1,150 @@
+package jumphash_test
+
+import (
+	"strconv"
+	"testing"
+
+	. "github.com/andy2046/gopie/pkg/jumphash"
+)
+
+const mementoKeys = 100000
+
+func mementoNodes(n int) []string {
+	nodes := make([]string, n)
+	for i := range nodes {
+		nodes[i] = "10.0.0." + strconv.Itoa(i) + ":80"
+	}
+	return nodes
+}
+
+func mementoMapping(t *testing.T, m *Memento) map[string]string {
+	mapping := make(map[string]string, mementoKeys)
+	for i := 0; i < mementoKeys; i++ {
+		key := strconv.Itoa(i)
+		node, err := m.Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mapping[key] = node
+	}
+	return mapping
+}
+
+func checkBalance(t *testing.T, m *Memento, mapping map[string]string) {
+	counts := map[string]int{}
+	for _, node := range mapping {
+		counts[node]++
+	}
+	if len(counts) != m.Len() {
+		t.Fatalf("expected %d nodes in use, got %d", m.Len(), len(counts))
+	}
+	expected := mementoKeys / m.Len()
+	for node, c := range counts {
+		if c < expected*8/10 || c > expected*12/10 {
+			t.Fatalf("node %s got %d keys, expected about %d", node, c, expected)
+		}
+	}
+}
+
+func TestMementoNoNode(t *testing.T) {
+	m := NewMemento()
+	if _, err := m.Get("key"); err != ErrNoNode {
+		t.Fatalf("expected ErrNoNode, got %v", err)
+	}
+
+	m.AddNode("a")
+	m.RemoveNode("a")
+	if _, err := m.Get("key"); err != ErrNoNode {
+		t.Fatalf("expected ErrNoNode, got %v", err)
+	}
+}
+
+func TestMementoJumpCompatible(t *testing.T) {
+	nodes := mementoNodes(10)
+	m := NewMemento(nodes...)
+	for _, v := range jumphashStringTest {
+		node, _ := m.Get(v.key)
+		if node != nodes[v.expected] {
+			t.Errorf("invalid node for key=%s, expected %s, got %s",
+				strconv.Quote(v.key), nodes[v.expected], node)
+		}
+	}
+}
+
+func TestMementoRemove(t *testing.T) {
+	m := NewMemento(mementoNodes(10)...)
+	before := mementoMapping(t, m)
+
+	removed := []string{"10.0.0.3:80", "10.0.0.7:80", "10.0.0.0:80"}
+	for _, r := range removed {
+		if !m.RemoveNode(r) {
+			t.Fatalf("fail to remove node %s", r)
+		}
+		after := mementoMapping(t, m)
+		for key, node := range after {
+			if node == r {
+				t.Fatalf("key %s mapped to removed node %s", key, r)
+			}
+			if before[key] != r && before[key] != node {
+				t.Fatalf("key %s moved from %s to %s", key, before[key], node)
+			}
+		}
+		checkBalance(t, m, after)
+		before = after
+	}
+
+	if m.RemoveNode("10.0.0.3:80") {
+		t.Fatal("node should be removed already")
+	}
+	if m.Len() != 7 || len(m.Nodes()) != 7 {
+		t.Fatalf("expected 7 nodes, got %v", m.Nodes())
+	}
+}
+
+func TestMementoAdd(t *testing.T) {
+	m := NewMemento(mementoNodes(10)...)
+	m.RemoveNode("10.0.0.4:80")
+	m.RemoveNode("10.0.0.9:80")
+	before := mementoMapping(t, m)
+
+	added := []string{"10.0.1.0:80", "10.0.1.1:80", "10.0.1.2:80"}
+	for _, a := range added {
+		m.AddNode(a)
+		after := mementoMapping(t, m)
+		for key, node := range after {
+			if node != a && before[key] != node {
+				t.Fatalf("key %s moved from %s to %s", key, before[key], node)
+			}
+		}
+		checkBalance(t, m, after)
+		before = after
+	}
+}
+
+func TestMementoRestore(t *testing.T) {
+	m := NewMemento(mementoNodes(10)...)
+	before := mementoMapping(t, m)
+
+	m.RemoveNode("10.0.0.2:80")
+	m.RemoveNode("10.0.0.5:80")
+	m.AddNode("10.0.0.5:80")
+	m.AddNode("10.0.0.2:80")
+
+	after := mementoMapping(t, m)
+	for key, node := range after {
+		if before[key] != node {
+			t.Fatalf("key %s moved from %s to %s", key, before[key], node)
+		}
+	}
+}
+
+func BenchmarkMementoGet(b *testing.B) {
+	m := NewMemento(mementoNodes(100)...)
+	for i := 0; i < 100; i += 3 {
+		m.RemoveNode("10.0.0." + strconv.Itoa(i) + ":80")
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Get(strconv.Itoa(i))
+	}
+}
@@ -0,0 +1,193 @@
+package lru
+
+/*
+   https://www.usenix.org/legacy/events/fast03/tech/full_papers/megiddo/megiddo.pdf
+*/
+
+import (
+	"container/list"
+	"sync"
+)
+
+// ARC is an Adaptive Replacement Cache,
+// which balances between recency and frequency by tracking the recently evicted keys.
+type ARC struct {
+	size int
+	// p is the target size of t1.
+	p int
+
+	// t1 holds the entries seen once recently.
+	t1 *list.List
+	// t2 holds the entries seen at least twice recently.
+	t2 *list.List
+	// b1 holds the keys evicted from t1.
+	b1 *list.List
+	// b2 holds the keys evicted from t2.
+	b2 *list.List
+
+	items map[interface{}]*list.Element
+	mu    sync.Mutex
+}
+
+// NewARC creates a new ARC holding at most size entries.
+func NewARC(size int) *ARC {
+	if size <= 0 {
+		panic("size must be positive int")
+	}
+	c := &ARC{size: size}
+	c.Clear()
+	return c
+}
+
+// Add adds value to the cache.
+func (c *ARC) Add(key interface{}, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		l := c.listOf(e)
+		switch l {
+		case c.t1, c.t2:
+			e.Value.(*arcEntry).value = value
+			c.moveTo(e, c.t2)
+			return
+		case c.b1:
+			delta := 1
+			if c.b1.Len() < c.b2.Len() {
+				delta = c.b2.Len() / c.b1.Len()
+			}
+			c.p = min(c.size, c.p+delta)
+			c.replace(false)
+		case c.b2:
+			delta := 1
+			if c.b2.Len() < c.b1.Len() {
+				delta = c.b1.Len() / c.b2.Len()
+			}
+			c.p = max(0, c.p-delta)
+			c.replace(true)
+		}
+		l.Remove(e)
+		c.items[key] = c.t2.PushFront(&arcEntry{entry{key: key, value: value}, c.t2})
+		return
+	}
+
+	if c.t1.Len()+c.b1.Len() >= c.size {
+		if c.t1.Len() < c.size {
+			c.removeBack(c.b1)
+			c.replace(false)
+		} else {
+			c.removeBack(c.t1)
+		}
+	} else if total := c.t1.Len() + c.t2.Len() + c.b1.Len() + c.b2.Len(); total >= c.size {
+		if total >= 2*c.size {
+			c.removeBack(c.b2)
+		}
+		c.replace(false)
+	}
+	c.items[key] = c.t1.PushFront(&arcEntry{entry{key: key, value: value}, c.t1})
+}
+
+// Get looks up value by key from the cache.
+func (c *ARC) Get(key interface{}) (value interface{}, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	if l := c.listOf(e); l != c.t1 && l != c.t2 {
+		return nil, false
+	}
+	c.moveTo(e, c.t2)
+	return e.Value.(*arcEntry).value, true
+}
+
+// Remove removes the provided key from the cache.
+func (c *ARC) Remove(key interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.listOf(e).Remove(e)
+		delete(c.items, key)
+	}
+}
+
+// Len returns the number of items in the cache.
+func (c *ARC) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.t1.Len() + c.t2.Len()
+}
+
+// Clear purges all items from the cache.
+func (c *ARC) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.p = 0
+	c.t1, c.t2 = list.New(), list.New()
+	c.b1, c.b2 = list.New(), list.New()
+	c.items = make(map[interface{}]*list.Element)
+}
+
+type arcEntry struct {
+	entry
+	list *list.List
+}
+
+func (c *ARC) listOf(e *list.Element) *list.List {
+	return e.Value.(*arcEntry).list
+}
+
+func (c *ARC) moveTo(e *list.Element, l *list.List) {
+	ae := e.Value.(*arcEntry)
+	if ae.list == l {
+		l.MoveToFront(e)
+		return
+	}
+	ae.list.Remove(e)
+	ae.list = l
+	c.items[ae.key] = l.PushFront(ae)
+}
+
+// replace evicts an entry from t1 or t2 into the ghost lists if the cache is full.
+func (c *ARC) replace(inB2 bool) {
+	if c.t1.Len()+c.t2.Len() < c.size {
+		return
+	}
+	if c.t1.Len() > 0 && (c.t1.Len() > c.p || (inB2 && c.t1.Len() == c.p) || c.t2.Len() == 0) {
+		c.toGhost(c.t1.Back(), c.b1)
+	} else if c.t2.Len() > 0 {
+		c.toGhost(c.t2.Back(), c.b2)
+	}
+}
+
+func (c *ARC) toGhost(e *list.Element, ghost *list.List) {
+	ae := e.Value.(*arcEntry)
+	ae.value = nil
+	c.moveTo(e, ghost)
+}
+
+func (c *ARC) removeBack(l *list.List) {
+	if e := l.Back(); e != nil {
+		l.Remove(e)
+		delete(c.items, e.Value.(*arcEntry).key)
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
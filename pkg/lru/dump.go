@@ -0,0 +1,147 @@
+package lru
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/gob"
+	"io"
+	"time"
+)
+
+type (
+	// Codec encodes and decodes the keys and values for Dump and Load.
+	Codec interface {
+		// Encode encodes v into bytes.
+		Encode(v interface{}) ([]byte, error)
+		// Decode decodes bytes into value.
+		Decode(data []byte) (interface{}, error)
+	}
+
+	// GobCodec is the Codec using encoding/gob,
+	// types other than the builtin ones must be registered by gob.Register.
+	GobCodec struct{}
+
+	// record is an entry in the dump.
+	record struct {
+		Key   []byte
+		Value []byte
+		// Expire is the expiration time in UnixNano, zero means no expiration.
+		Expire int64
+		Cost   int64
+	}
+)
+
+// Encode implements Codec interface.
+func (GobCodec) Encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode implements Codec interface.
+func (GobCodec) Decode(data []byte) (interface{}, error) {
+	var v interface{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Dump writes all the items not expired from the cache to w in MRU order,
+// the keys and values are encoded by Codec.
+func (c *Cache) Dump(w io.Writer) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.cache == nil {
+		return nil
+	}
+
+	codec := c.codec()
+	enc := gob.NewEncoder(w)
+	now := c.timeNow()
+	for e := c.ll.Front(); e != nil; e = e.Next() {
+		kv := e.Value.(*entry)
+		if c.expired(kv, now) {
+			continue
+		}
+
+		rec := record{Cost: kv.cost}
+		if !kv.expire.IsZero() {
+			rec.Expire = kv.expire.UnixNano()
+		}
+		var err error
+		if rec.Key, err = codec.Encode(kv.key); err != nil {
+			return err
+		}
+		if rec.Value, err = codec.Encode(kv.value); err != nil {
+			return err
+		}
+		if err = enc.Encode(&rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load reads the items written by Dump from r into the cache,
+// the items are added as less recently used than the items already in the cache.
+// The keys already in the cache, the expired items and the items beyond the capacity are skipped.
+func (c *Cache) Load(r io.Reader) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cache == nil {
+		c.cache = make(map[interface{}]*list.Element)
+		c.ll = list.New()
+	}
+
+	codec := c.codec()
+	dec := gob.NewDecoder(r)
+	now := c.timeNow()
+	for {
+		var rec record
+		if err := dec.Decode(&rec); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		kv := &entry{cost: rec.Cost}
+		if rec.Expire != 0 {
+			kv.expire = time.Unix(0, rec.Expire)
+			if c.expired(kv, now) {
+				continue
+			}
+		}
+		if c.MaxEntries > 0 && c.ll.Len() >= c.MaxEntries {
+			return nil
+		}
+		if c.MaxCost > 0 && c.cost+kv.cost > c.MaxCost {
+			continue
+		}
+
+		var err error
+		if kv.key, err = codec.Decode(rec.Key); err != nil {
+			return err
+		}
+		if _, ok := c.cache[kv.key]; ok {
+			continue
+		}
+		if kv.value, err = codec.Decode(rec.Value); err != nil {
+			return err
+		}
+
+		c.cache[kv.key] = c.ll.PushBack(kv)
+		c.cost += kv.cost
+	}
+}
+
+func (c *Cache) codec() Codec {
+	if c.Codec != nil {
+		return c.Codec
+	}
+	return GobCodec{}
+}
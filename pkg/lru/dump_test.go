@@ -0,0 +1,89 @@
+package lru
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Decode(data []byte) (interface{}, error) {
+	var v interface{}
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+func TestDumpLoad(t *testing.T) {
+	lru := New(0)
+	lru.Add("a", 1)
+	lru.AddWithTTL("b", "two", time.Hour)
+	lru.AddWithTTL("expired", 3, time.Nanosecond)
+	lru.AddWithCost("c", []byte("three"), 5)
+	time.Sleep(time.Millisecond)
+
+	var buf bytes.Buffer
+	if err := lru.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	warm := New(0)
+	warm.Add("z", 0)
+	if err := warm.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if warm.Len() != 4 || warm.Cost() != 8 {
+		t.Fatalf("got %d entries with cost %d; want 4 with cost 8", warm.Len(), warm.Cost())
+	}
+	if _, ok := warm.Get("expired"); ok {
+		t.Fatal("expired entry should not be loaded")
+	}
+	if v, ok := warm.Get("c"); !ok || string(v.([]byte)) != "three" {
+		t.Fatalf("got %v for key c; want three", v)
+	}
+
+	// the loaded entries are in MRU order after the existing ones.
+	var order []interface{}
+	for e := warm.ll.Front(); e != nil; e = e.Next() {
+		order = append(order, e.Value.(*entry).key)
+	}
+	expected := []interface{}{"c", "z", "b", "a"}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("got order %v; want %v", order, expected)
+		}
+	}
+}
+
+func TestLoadCapacity(t *testing.T) {
+	lru := New(0)
+	lru.Codec = jsonCodec{}
+	for i := 0; i < 10; i++ {
+		lru.Add(i, i)
+	}
+
+	var buf bytes.Buffer
+	if err := lru.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	warm := New(3)
+	warm.Codec = jsonCodec{}
+	if err := warm.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if warm.Len() != 3 {
+		t.Fatalf("got %d entries; want 3", warm.Len())
+	}
+	// json decodes numbers into float64.
+	for _, k := range []float64{9, 8, 7} {
+		if _, ok := warm.Get(k); !ok {
+			t.Fatalf("most recently used key %v should be loaded", k)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package lru
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type (
+	// Loader loads the value associated with key on cache miss.
+	Loader func(ctx context.Context, key interface{}) (interface{}, error)
+
+	// call is an in-flight or completed Loader call.
+	call struct {
+		done  chan struct{}
+		value interface{}
+		err   error
+	}
+
+	// failure is a cached Loader error.
+	failure struct {
+		err    error
+		expire time.Time
+	}
+)
+
+// ErrLoaderPanic is returned to the callers waiting for a Loader which panics.
+var ErrLoaderPanic = errors.New("loader panics")
+
+// GetOrLoad looks up value by key from the cache,
+// on cache miss, value is loaded by loader and added to the cache.
+// Concurrent callers for the same missing key share one loader call,
+// which uses the ctx of the caller who starts it.
+// The error returned by loader is not cached unless NegativeTTL is positive.
+func (c *Cache) GetOrLoad(ctx context.Context, key interface{}, loader Loader) (interface{}, error) {
+	if v, ok := c.Get(key); ok {
+		return v, nil
+	}
+
+	c.loadMu.Lock()
+	// the value may be added while waiting for the lock.
+	if v, ok := c.get(key, false); ok {
+		c.loadMu.Unlock()
+		return v, nil
+	}
+	if f, ok := c.failures[key]; ok {
+		if c.timeNow().Before(f.expire) {
+			c.loadMu.Unlock()
+			return nil, f.err
+		}
+		delete(c.failures, key)
+	}
+	if cl, ok := c.calls[key]; ok {
+		c.loadMu.Unlock()
+		select {
+		case <-cl.done:
+			return cl.value, cl.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if c.calls == nil {
+		c.calls = make(map[interface{}]*call)
+	}
+	cl := &call{done: make(chan struct{})}
+	c.calls[key] = cl
+	c.loadMu.Unlock()
+
+	c.load(ctx, key, loader, cl)
+	return cl.value, cl.err
+}
+
+func (c *Cache) load(ctx context.Context, key interface{}, loader Loader, cl *call) {
+	defer func() {
+		if r := recover(); r != nil {
+			cl.err = ErrLoaderPanic
+			c.finish(key, cl)
+			panic(r)
+		}
+		c.finish(key, cl)
+	}()
+
+	cl.value, cl.err = loader(ctx, key)
+	if cl.err == nil {
+		c.Add(key, cl.value)
+	}
+}
+
+func (c *Cache) finish(key interface{}, cl *call) {
+	c.loadMu.Lock()
+	delete(c.calls, key)
+	if cl.err != nil && cl.err != ErrLoaderPanic && c.NegativeTTL > 0 {
+		if c.failures == nil {
+			c.failures = make(map[interface{}]failure)
+		}
+		c.failures[key] = failure{cl.err, c.timeNow().Add(c.NegativeTTL)}
+	}
+	c.loadMu.Unlock()
+	close(cl.done)
+}
+
+// forget removes the cached Loader error for key.
+func (c *Cache) forget(key interface{}) {
+	c.loadMu.Lock()
+	delete(c.failures, key)
+	c.loadMu.Unlock()
+}
+
+// forgetExpired removes all the expired Loader errors.
+func (c *Cache) forgetExpired(now time.Time) {
+	c.loadMu.Lock()
+	for k, f := range c.failures {
+		if !now.Before(f.expire) {
+			delete(c.failures, k)
+		}
+	}
+	c.loadMu.Unlock()
+}
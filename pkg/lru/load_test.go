@@ -0,0 +1,103 @@
+package lru
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoad(t *testing.T) {
+	lru := New(0)
+	var calls int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context, key interface{}) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return key.(string) + "-loaded", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := lru.GetOrLoad(context.Background(), "myKey", loader)
+			if err != nil || v != "myKey-loaded" {
+				t.Errorf("got %v, %v; want myKey-loaded", v, err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("loader called %d times; want 1", n)
+	}
+	if v, ok := lru.Get("myKey"); !ok || v != "myKey-loaded" {
+		t.Fatalf("loaded value not added to the cache, got %v", v)
+	}
+}
+
+func TestGetOrLoadError(t *testing.T) {
+	errLoad := errors.New("load error")
+	calls := 0
+	loader := func(ctx context.Context, key interface{}) (interface{}, error) {
+		calls++
+		return nil, errLoad
+	}
+
+	lru := New(0)
+	for i := 0; i < 2; i++ {
+		if _, err := lru.GetOrLoad(context.Background(), "myKey", loader); err != errLoad {
+			t.Fatalf("got error %v; want %v", err, errLoad)
+		}
+	}
+	if calls != 2 || lru.Len() != 0 {
+		t.Fatalf("error should not be cached, loader called %d times", calls)
+	}
+
+	clock := &fakeClock{time.Now()}
+	calls = 0
+	lru = New(0)
+	lru.NegativeTTL = time.Second
+	lru.now = clock.now
+	for i := 0; i < 2; i++ {
+		if _, err := lru.GetOrLoad(context.Background(), "myKey", loader); err != errLoad {
+			t.Fatalf("got error %v; want %v", err, errLoad)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("error should be cached, loader called %d times", calls)
+	}
+
+	clock.advance(time.Second)
+	lru.GetOrLoad(context.Background(), "myKey", loader)
+	if calls != 2 {
+		t.Fatalf("cached error should expire, loader called %d times", calls)
+	}
+}
+
+func TestGetOrLoadContext(t *testing.T) {
+	lru := New(0)
+	release := make(chan struct{})
+	go lru.GetOrLoad(context.Background(), "myKey", func(ctx context.Context, key interface{}) (interface{}, error) {
+		<-release
+		return 1234, nil
+	})
+	defer close(release)
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := lru.GetOrLoad(ctx, "myKey", func(ctx context.Context, key interface{}) (interface{}, error) {
+		t.Fatal("loader should not be called while another load is in flight")
+		return nil, nil
+	})
+	if err != context.DeadlineExceeded {
+		t.Fatalf("got error %v; want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -4,26 +4,106 @@ package lru
 import (
 	"container/list"
 	"sync"
+	"time"
 )
 
+// Reason is the reason why an entry is purged from the cache.
+type Reason int
+
+const (
+	// ReasonRemoved is for the entry removed by Remove or Clear.
+	ReasonRemoved Reason = iota
+	// ReasonEvicted is for the entry evicted to make room for new entry.
+	ReasonEvicted
+	// ReasonExpired is for the entry which is expired.
+	ReasonExpired
+)
+
+// Stats is the statistics of the cache.
+type Stats struct {
+	// Hits is the number of Get found the key.
+	Hits uint64
+	// Misses is the number of Get not found the key.
+	Misses uint64
+	// Adds is the number of new entries added.
+	Adds uint64
+	// Updates is the number of existing entries updated.
+	Updates uint64
+	// Evictions is the number of entries evicted to make room for new entry.
+	Evictions uint64
+	// Expirations is the number of expired entries removed.
+	Expirations uint64
+}
+
 // Cache is a LRU cache.
 type Cache struct {
 	// MaxEntries is the maximum number of cache entries
 	// before an item is purged. Zero means no limit.
 	MaxEntries int
 
+	// MaxCost is the maximum total cost of cache entries
+	// before an item is purged. Zero means no limit.
+	MaxCost int64
+
+	// CostFunc computes the cost of the entries added by Add and AddWithTTL.
+	// If CostFunc is nil, the cost of an entry is 1.
+	CostFunc func(key interface{}, value interface{}) int64
+
+	// TTL is the default time to live of the entries added by Add.
+	// Zero means no expiration.
+	TTL time.Duration
+
+	// NegativeTTL is the time to live of the errors returned by Loader in GetOrLoad.
+	// Zero means the errors are not cached.
+	NegativeTTL time.Duration
+
+	// Codec encodes and decodes the keys and values for Dump and Load.
+	// If Codec is nil, GobCodec is used.
+	Codec Codec
+
 	// OnPurged specifies a function to be executed
 	// when an entry is purged from the cache.
 	OnPurged func(key interface{}, value interface{})
 
+	// OnPurgedWithReason specifies a function to be executed
+	// when an entry is purged from the cache, with the reason of purge.
+	OnPurgedWithReason func(key interface{}, value interface{}, reason Reason)
+
 	ll    *list.List
 	cache map[interface{}]*list.Element
+	cost  int64
+	stats Stats
 	mu    sync.RWMutex
+
+	now       func() time.Time
+	done      chan struct{}
+	closeOnce sync.Once
+
+	loadMu   sync.Mutex
+	calls    map[interface{}]*call
+	failures map[interface{}]failure
 }
 
 type entry struct {
 	key   interface{}
 	value interface{}
+	// expire is the expiration time, zero means no expiration.
+	expire time.Time
+	cost   int64
+}
+
+// String implements stringer interface.
+func (r Reason) String() string {
+	switch r {
+	case ReasonRemoved:
+		return "removed"
+	case ReasonEvicted:
+		return "evicted"
+	case ReasonExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
 }
 
 // New creates a new cache, if maxEntries is zero, the cache has no limit.
@@ -38,8 +118,54 @@ func New(maxEntries int) *Cache {
 	}
 }
 
-// Add adds value to the cache.
+// NewCost creates a new cache limited by the total cost of entries,
+// if costFunc is nil, the cost of an entry is 1.
+func NewCost(maxCost int64, costFunc func(key interface{}, value interface{}) int64) *Cache {
+	if maxCost < 0 {
+		panic("maxCost can not be less than zero")
+	}
+	c := New(0)
+	c.MaxCost = maxCost
+	c.CostFunc = costFunc
+	return c
+}
+
+// NewTTL creates a new cache with the default ttl of entries,
+// if interval is positive, a janitor goroutine removes the expired entries every interval
+// until Close is called.
+func NewTTL(maxEntries int, ttl, interval time.Duration) *Cache {
+	c := New(maxEntries)
+	c.TTL = ttl
+	if interval > 0 {
+		c.done = make(chan struct{})
+		go c.janitor(interval)
+	}
+	return c
+}
+
+// Add adds value to the cache with the default TTL.
 func (c *Cache) Add(key interface{}, value interface{}) {
+	c.AddWithTTL(key, value, c.TTL)
+}
+
+// AddWithTTL adds value to the cache with the given ttl,
+// if ttl is zero, the entry never expires.
+func (c *Cache) AddWithTTL(key interface{}, value interface{}, ttl time.Duration) {
+	var cost int64 = 1
+	if c.CostFunc != nil {
+		cost = c.CostFunc(key, value)
+	}
+	c.add(key, value, ttl, cost)
+}
+
+// AddWithCost adds value to the cache with the default TTL and the given cost,
+// the oldest items are purged until the total cost is not more than MaxCost,
+// including the added one if its cost is more than MaxCost.
+func (c *Cache) AddWithCost(key interface{}, value interface{}, cost int64) {
+	c.add(key, value, c.TTL, cost)
+}
+
+func (c *Cache) add(key interface{}, value interface{}, ttl time.Duration, cost int64) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -47,35 +173,67 @@ func (c *Cache) Add(key interface{}, value interface{}) {
 		c.cache = make(map[interface{}]*list.Element)
 		c.ll = list.New()
 	}
+	var expire time.Time
+	if ttl > 0 {
+		expire = c.timeNow().Add(ttl)
+	}
 	if e, ok := c.cache[key]; ok {
 		c.ll.MoveToFront(e)
-		e.Value.(*entry).value = value
-		return
+		kv := e.Value.(*entry)
+		kv.value = value
+		kv.expire = expire
+		c.cost += cost - kv.cost
+		kv.cost = cost
+		c.stats.Updates++
+	} else {
+		ele := c.ll.PushFront(&entry{key, value, expire, cost})
+		c.cache[key] = ele
+		c.cost += cost
+		c.stats.Adds++
+		if c.MaxEntries > 0 && c.ll.Len() > c.MaxEntries {
+			c.removeOldest(false)
+		}
 	}
-	ele := c.ll.PushFront(&entry{key, value})
-	c.cache[key] = ele
-	if c.MaxEntries > 0 && c.ll.Len() > c.MaxEntries {
+	for c.MaxCost > 0 && c.cost > c.MaxCost && c.ll.Len() > 0 {
 		c.removeOldest(false)
 	}
 }
 
 // Get looks up value by key from the cache.
 func (c *Cache) Get(key interface{}) (value interface{}, ok bool) {
+	return c.get(key, true)
+}
+
+// get looks up value by key, hit or miss is counted in stats if count is true.
+func (c *Cache) get(key interface{}, count bool) (value interface{}, ok bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.cache == nil {
-		return
+	if c.cache != nil {
+		if ele, hit := c.cache[key]; hit {
+			if c.expired(ele.Value.(*entry), c.timeNow()) {
+				c.removeElement(ele, ReasonExpired)
+			} else {
+				c.ll.MoveToFront(ele)
+				value, ok = ele.Value.(*entry).value, true
+			}
+		}
 	}
-	if ele, hit := c.cache[key]; hit {
-		c.ll.MoveToFront(ele)
-		return ele.Value.(*entry).value, true
+
+	if count {
+		if ok {
+			c.stats.Hits++
+		} else {
+			c.stats.Misses++
+		}
 	}
 	return
 }
 
 // Remove removes the provided key from the cache.
 func (c *Cache) Remove(key interface{}) {
+	c.forget(key)
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -83,7 +241,7 @@ func (c *Cache) Remove(key interface{}) {
 		return
 	}
 	if ele, hit := c.cache[key]; hit {
-		c.removeElement(ele)
+		c.removeElement(ele, ReasonRemoved)
 	}
 }
 
@@ -103,20 +261,89 @@ func (c *Cache) removeOldest(toLock bool) {
 	}
 	ele := c.ll.Back()
 	if ele != nil {
-		c.removeElement(ele)
+		c.removeElement(ele, ReasonEvicted)
 	}
 }
 
-func (c *Cache) removeElement(e *list.Element) {
+// RemoveExpired removes all the expired items from the cache.
+func (c *Cache) RemoveExpired() {
+	now := c.timeNow()
+	c.forgetExpired(now)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cache == nil {
+		return
+	}
+	for e := c.ll.Back(); e != nil; {
+		prev := e.Prev()
+		if c.expired(e.Value.(*entry), now) {
+			c.removeElement(e, ReasonExpired)
+		}
+		e = prev
+	}
+}
+
+// Close stops the janitor goroutine if any.
+func (c *Cache) Close() {
+	c.closeOnce.Do(func() {
+		if c.done != nil {
+			close(c.done)
+		}
+	})
+}
+
+func (c *Cache) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.RemoveExpired()
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *Cache) removeElement(e *list.Element, reason Reason) {
 	c.ll.Remove(e)
 	kv := e.Value.(*entry)
 	delete(c.cache, kv.key)
+	c.cost -= kv.cost
+	switch reason {
+	case ReasonEvicted:
+		c.stats.Evictions++
+	case ReasonExpired:
+		c.stats.Expirations++
+	}
+	c.purged(kv, reason)
+}
+
+func (c *Cache) purged(kv *entry, reason Reason) {
 	if c.OnPurged != nil {
 		c.OnPurged(kv.key, kv.value)
 	}
+	if c.OnPurgedWithReason != nil {
+		c.OnPurgedWithReason(kv.key, kv.value, reason)
+	}
+}
+
+func (c *Cache) expired(kv *entry, now time.Time) bool {
+	return !kv.expire.IsZero() && !now.Before(kv.expire)
+}
+
+func (c *Cache) timeNow() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
 }
 
-// Len returns the number of items in the cache.
+// Len returns the number of items in the cache,
+// including the expired items not removed yet.
 func (c *Cache) Len() int {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -127,17 +354,35 @@ func (c *Cache) Len() int {
 	return c.ll.Len()
 }
 
+// Cost returns the total cost of items in the cache.
+func (c *Cache) Cost() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cost
+}
+
+// Stats returns the statistics of the cache.
+func (c *Cache) Stats() Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.stats
+}
+
 // Clear purges all items from the cache.
 func (c *Cache) Clear() {
+	c.loadMu.Lock()
+	c.failures = nil
+	c.loadMu.Unlock()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.OnPurged != nil {
-		for _, e := range c.cache {
-			kv := e.Value.(*entry)
-			c.OnPurged(kv.key, kv.value)
-		}
+	for _, e := range c.cache {
+		c.purged(e.Value.(*entry), ReasonRemoved)
 	}
 	c.ll = nil
 	c.cache = nil
+	c.cost = 0
 }
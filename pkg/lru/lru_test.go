@@ -3,6 +3,7 @@ package lru
 import (
 	"fmt"
 	"testing"
+	"time"
 )
 
 type simpleStruct struct {
@@ -80,6 +81,139 @@ func TestPurge(t *testing.T) {
 	}
 }
 
+func TestCost(t *testing.T) {
+	purgedKeys := make([]interface{}, 0)
+	lru := NewCost(100, func(key interface{}, value interface{}) int64 {
+		return int64(len(value.(string)))
+	})
+	lru.OnPurged = func(key interface{}, value interface{}) {
+		purgedKeys = append(purgedKeys, key)
+	}
+
+	lru.Add("a", string(make([]byte, 40)))
+	lru.Add("b", string(make([]byte, 40)))
+	if lru.Cost() != 80 {
+		t.Fatalf("got cost %d; want 80", lru.Cost())
+	}
+
+	lru.Add("c", string(make([]byte, 70)))
+	if lru.Cost() != 70 || lru.Len() != 1 {
+		t.Fatalf("got cost %d with %d entries; want 70 with 1", lru.Cost(), lru.Len())
+	}
+	if len(purgedKeys) != 2 || purgedKeys[0] != "a" || purgedKeys[1] != "b" {
+		t.Fatalf("got evicted keys %v; want [a b]", purgedKeys)
+	}
+
+	lru.AddWithCost("c", "small", 10)
+	lru.AddWithCost("d", "large", 120)
+	if _, ok := lru.Get("d"); ok {
+		t.Fatal("entry with cost more than MaxCost should not be cached")
+	}
+	if lru.Cost() != 0 || lru.Len() != 0 {
+		t.Fatalf("got cost %d with %d entries; want 0 with 0", lru.Cost(), lru.Len())
+	}
+
+	lru.AddWithCost("e", "e", 30)
+	lru.Remove("e")
+	if lru.Cost() != 0 {
+		t.Fatalf("got cost %d; want 0", lru.Cost())
+	}
+}
+
+func TestStats(t *testing.T) {
+	lru := New(2)
+	lru.Add("a", 1)
+	lru.Add("b", 2)
+	lru.Add("a", 3)
+	lru.Add("c", 4)
+	lru.Get("a")
+	lru.Get("b")
+	lru.AddWithTTL("d", 5, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	lru.Get("d")
+
+	expected := Stats{Hits: 1, Misses: 2, Adds: 4, Updates: 1, Evictions: 2, Expirations: 1}
+	if st := lru.Stats(); st != expected {
+		t.Fatalf("got stats %+v; want %+v", st, expected)
+	}
+}
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (f *fakeClock) now() time.Time { return f.t }
+
+func (f *fakeClock) advance(d time.Duration) { f.t = f.t.Add(d) }
+
+func TestTTL(t *testing.T) {
+	clock := &fakeClock{time.Now()}
+	reasons := map[interface{}]Reason{}
+	lru := New(0)
+	lru.TTL = time.Minute
+	lru.now = clock.now
+	lru.OnPurgedWithReason = func(key interface{}, value interface{}, reason Reason) {
+		reasons[key] = reason
+	}
+
+	lru.Add("default", 1)
+	lru.AddWithTTL("short", 2, time.Second)
+	lru.AddWithTTL("forever", 3, 0)
+
+	clock.advance(time.Second)
+	if _, ok := lru.Get("short"); ok {
+		t.Fatal("expired entry should be invisible to Get")
+	}
+	if reasons["short"] != ReasonExpired {
+		t.Fatalf("got reason %v; want %v", reasons["short"], ReasonExpired)
+	}
+	if _, ok := lru.Get("default"); !ok {
+		t.Fatal("entry with default TTL should not expire yet")
+	}
+
+	clock.advance(time.Minute)
+	lru.RemoveExpired()
+	if lru.Len() != 1 {
+		t.Fatalf("got %d entries; want 1", lru.Len())
+	}
+	if _, ok := lru.Get("forever"); !ok {
+		t.Fatal("entry without TTL should never expire")
+	}
+	if reasons["default"] != ReasonExpired {
+		t.Fatalf("got reason %v; want %v", reasons["default"], ReasonExpired)
+	}
+
+	lru.Remove("forever")
+	if reasons["forever"] != ReasonRemoved {
+		t.Fatalf("got reason %v; want %v", reasons["forever"], ReasonRemoved)
+	}
+}
+
+func TestJanitor(t *testing.T) {
+	purged := make(chan Reason, 1)
+	lru := NewTTL(0, 10*time.Millisecond, 5*time.Millisecond)
+	defer lru.Close()
+	lru.OnPurgedWithReason = func(key interface{}, value interface{}, reason Reason) {
+		purged <- reason
+	}
+
+	lru.Add("myKey", 1234)
+	select {
+	case r := <-purged:
+		if r != ReasonExpired {
+			t.Fatalf("got reason %v; want %v", r, ReasonExpired)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expired entry not removed by janitor")
+	}
+	if lru.Len() != 0 {
+		t.Fatalf("got %d entries; want 0", lru.Len())
+	}
+
+	lru.Close()
+	lru.Close()
+}
+
 func BenchmarkLRU(b *testing.B) {
 	purgedKeys := make([]interface{}, 0)
 	onPurgedFun := func(key interface{}, value interface{}) {
@@ -0,0 +1,55 @@
+package lru
+
+import (
+	"errors"
+)
+
+// Policy is the cache interface shared by the eviction policies,
+// it is implemented by *Cache, *Sharded, *ARC, *TwoQueue and *TinyLFU.
+type Policy interface {
+	// Add adds value to the cache.
+	Add(key interface{}, value interface{})
+	// Get looks up value by key from the cache.
+	Get(key interface{}) (value interface{}, ok bool)
+	// Remove removes the provided key from the cache.
+	Remove(key interface{})
+	// Len returns the number of items in the cache.
+	Len() int
+	// Clear purges all items from the cache.
+	Clear()
+}
+
+const (
+	// PolicyLRU is the name of the least recently used policy.
+	PolicyLRU = "lru"
+	// PolicyARC is the name of the adaptive replacement cache policy.
+	PolicyARC = "arc"
+	// PolicyTwoQueue is the name of the 2Q policy.
+	PolicyTwoQueue = "2q"
+	// PolicyTinyLFU is the name of the W-TinyLFU policy.
+	PolicyTinyLFU = "tinylfu"
+)
+
+// ErrUnknownPolicy is returned by NewPolicy for unknown policy name.
+var ErrUnknownPolicy = errors.New("unknown cache policy")
+
+// NewPolicy creates a new cache holding at most size entries
+// with the eviction policy of the given name.
+func NewPolicy(name string, size int) (Policy, error) {
+	if size <= 0 {
+		return nil, errors.New("size must be positive")
+	}
+
+	switch name {
+	case PolicyLRU:
+		return New(size), nil
+	case PolicyARC:
+		return NewARC(size), nil
+	case PolicyTwoQueue:
+		return NewTwoQueue(size), nil
+	case PolicyTinyLFU:
+		return NewTinyLFU(size), nil
+	default:
+		return nil, ErrUnknownPolicy
+	}
+}
@@ -0,0 +1,144 @@
+package lru
+
+import (
+	"math/rand"
+	"testing"
+)
+
+var policies = []string{PolicyLRU, PolicyARC, PolicyTwoQueue, PolicyTinyLFU}
+
+var (
+	_ Policy = (*Cache)(nil)
+	_ Policy = (*Sharded)(nil)
+	_ Policy = (*ARC)(nil)
+	_ Policy = (*TwoQueue)(nil)
+	_ Policy = (*TinyLFU)(nil)
+)
+
+func TestNewPolicy(t *testing.T) {
+	if _, err := NewPolicy("unknown", 10); err != ErrUnknownPolicy {
+		t.Fatalf("got error %v; want %v", err, ErrUnknownPolicy)
+	}
+	if _, err := NewPolicy(PolicyLRU, 0); err == nil {
+		t.Fatal("size should be positive")
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	for _, name := range policies {
+		c, err := NewPolicy(name, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tt := range getTests {
+			c.Add(tt.keyToAdd, 1234)
+			val, ok := c.Get(tt.keyToGet)
+			if ok != tt.expectedOk {
+				t.Fatalf("%s %s: cache hit = %v; want %v", name, tt.name, ok, !ok)
+			} else if ok && val != 1234 {
+				t.Fatalf("%s %s expected get to return 1234 but got %v", name, tt.name, val)
+			}
+		}
+
+		c.Add("myKey", 1)
+		c.Add("myKey", 2)
+		if val, ok := c.Get("myKey"); !ok || val != 2 {
+			t.Fatalf("%s: got %v for updated key; want 2", name, val)
+		}
+		c.Remove("myKey")
+		if _, ok := c.Get("myKey"); ok {
+			t.Fatalf("%s: removed entry should not be returned", name)
+		}
+
+		for i := 0; i < 1000; i++ {
+			c.Add(i, i)
+			c.Get(i % 10)
+		}
+		if c.Len() > 100 {
+			t.Fatalf("%s: got %d entries; want at most 100", name, c.Len())
+		}
+
+		c.Clear()
+		if c.Len() != 0 {
+			t.Fatalf("%s: got %d entries after Clear; want 0", name, c.Len())
+		}
+	}
+}
+
+// trace returns a trace of zipf distributed keys interleaved with scans of unique keys.
+func trace(n int) []int {
+	r := rand.New(rand.NewSource(1))
+	zipf := rand.NewZipf(r, 1.1, 1, 999)
+	keys := make([]int, 0, n)
+	scan := 1000
+	for len(keys) < n {
+		for i := 0; i < 1000; i++ {
+			keys = append(keys, int(zipf.Uint64()))
+		}
+		for i := 0; i < 200; i++ {
+			keys = append(keys, scan)
+			scan++
+		}
+	}
+	return keys[:n]
+}
+
+func hitRatio(c Policy, keys []int) float64 {
+	hits := 0
+	for _, k := range keys {
+		if _, ok := c.Get(k); ok {
+			hits++
+		} else {
+			c.Add(k, k)
+		}
+	}
+	return float64(hits) / float64(len(keys))
+}
+
+func TestScanResistance(t *testing.T) {
+	keys := trace(100000)
+	ratios := map[string]float64{}
+	for _, name := range policies {
+		c, _ := NewPolicy(name, 100)
+		ratios[name] = hitRatio(c, keys)
+		t.Logf("%s hit ratio -> %.4f", name, ratios[name])
+	}
+	for _, name := range policies[1:] {
+		if ratios[name] <= ratios[PolicyLRU] {
+			t.Fatalf("%s hit ratio %.4f should be higher than lru %.4f",
+				name, ratios[name], ratios[PolicyLRU])
+		}
+	}
+}
+
+func TestTinyLFUAging(t *testing.T) {
+	c := NewTinyLFU(100)
+	for i := 0; i < c.sampleSize-1; i++ {
+		c.record(i % 10)
+	}
+	before := c.frequency(0)
+	// the frequency is aged on the sampleSize-th access.
+	c.record("new")
+	after := c.frequency(0)
+	if after < before/2 || after > before/2+1 {
+		t.Fatalf("frequency after aging %d should be about half of %d", after, before)
+	}
+	if after <= c.frequency("new") {
+		t.Fatalf("aged frequency %d should be higher than new entry %d", after, c.frequency("new"))
+	}
+}
+
+func BenchmarkTraceReplay(b *testing.B) {
+	keys := trace(100000)
+	for _, name := range policies {
+		b.Run(name, func(b *testing.B) {
+			var ratio float64
+			for i := 0; i < b.N; i++ {
+				c, _ := NewPolicy(name, 100)
+				ratio = hitRatio(c, keys)
+			}
+			b.Logf("hit ratio -> %.4f", ratio)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package lru
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Sharded is a LRU cache partitioned into independently locked shards by key hash,
+// which reduces lock contention under concurrent access.
+// The LRU order is maintained per shard.
+type Sharded struct {
+	shards []*Cache
+}
+
+const (
+	offset64 = 14695981039346656037
+	prime64  = 1099511628211
+)
+
+// NewSharded creates a new sharded cache with maxEntries split evenly across shards,
+// if maxEntries is zero, the cache has no limit.
+func NewSharded(shards, maxEntries int) *Sharded {
+	if maxEntries < 0 {
+		panic("maxEntries can not be less than zero")
+	}
+	perShard := 0
+	if maxEntries > 0 {
+		perShard = (maxEntries + shards - 1) / shards
+	}
+	return NewShardedFunc(shards, func() *Cache {
+		return New(perShard)
+	})
+}
+
+// NewShardedFunc creates a new sharded cache with each shard created by newCache.
+func NewShardedFunc(shards int, newCache func() *Cache) *Sharded {
+	if shards <= 0 {
+		panic("shards must be positive int")
+	}
+	s := &Sharded{shards: make([]*Cache, shards)}
+	for i := range s.shards {
+		s.shards[i] = newCache()
+	}
+	return s
+}
+
+// Add adds value to the cache with the default TTL.
+func (s *Sharded) Add(key interface{}, value interface{}) {
+	s.shard(key).Add(key, value)
+}
+
+// AddWithTTL adds value to the cache with the given ttl,
+// if ttl is zero, the entry never expires.
+func (s *Sharded) AddWithTTL(key interface{}, value interface{}, ttl time.Duration) {
+	s.shard(key).AddWithTTL(key, value, ttl)
+}
+
+// AddWithCost adds value to the cache with the default TTL and the given cost.
+func (s *Sharded) AddWithCost(key interface{}, value interface{}, cost int64) {
+	s.shard(key).AddWithCost(key, value, cost)
+}
+
+// Get looks up value by key from the cache.
+func (s *Sharded) Get(key interface{}) (value interface{}, ok bool) {
+	return s.shard(key).Get(key)
+}
+
+// GetOrLoad looks up value by key from the cache,
+// on cache miss, value is loaded by loader and added to the cache.
+func (s *Sharded) GetOrLoad(ctx context.Context, key interface{}, loader Loader) (interface{}, error) {
+	return s.shard(key).GetOrLoad(ctx, key, loader)
+}
+
+// Remove removes the provided key from the cache.
+func (s *Sharded) Remove(key interface{}) {
+	s.shard(key).Remove(key)
+}
+
+// RemoveExpired removes all the expired items from the cache.
+func (s *Sharded) RemoveExpired() {
+	for _, c := range s.shards {
+		c.RemoveExpired()
+	}
+}
+
+// Len returns the number of items in all the shards.
+func (s *Sharded) Len() int {
+	n := 0
+	for _, c := range s.shards {
+		n += c.Len()
+	}
+	return n
+}
+
+// Cost returns the total cost of items in all the shards.
+func (s *Sharded) Cost() int64 {
+	var n int64
+	for _, c := range s.shards {
+		n += c.Cost()
+	}
+	return n
+}
+
+// Stats returns the sum of the statistics of all the shards.
+func (s *Sharded) Stats() Stats {
+	var st Stats
+	for _, c := range s.shards {
+		cs := c.Stats()
+		st.Hits += cs.Hits
+		st.Misses += cs.Misses
+		st.Adds += cs.Adds
+		st.Updates += cs.Updates
+		st.Evictions += cs.Evictions
+		st.Expirations += cs.Expirations
+	}
+	return st
+}
+
+// Clear purges all items from the cache.
+func (s *Sharded) Clear() {
+	for _, c := range s.shards {
+		c.Clear()
+	}
+}
+
+// Close stops the janitor goroutines of all the shards if any.
+func (s *Sharded) Close() {
+	for _, c := range s.shards {
+		c.Close()
+	}
+}
+
+func (s *Sharded) shard(key interface{}) *Cache {
+	if len(s.shards) == 1 {
+		return s.shards[0]
+	}
+	return s.shards[hashKey(key)%uint64(len(s.shards))]
+}
+
+// hashKey uses FNV-1a to hash key,
+// the key not in builtin string or integer types is formatted with fmt first.
+func hashKey(key interface{}) uint64 {
+	switch k := key.(type) {
+	case string:
+		return hashString(k)
+	case int:
+		return hashUint64(uint64(k))
+	case int64:
+		return hashUint64(uint64(k))
+	case int32:
+		return hashUint64(uint64(k))
+	case uint:
+		return hashUint64(uint64(k))
+	case uint64:
+		return hashUint64(k)
+	case uint32:
+		return hashUint64(uint64(k))
+	default:
+		return hashString(fmt.Sprintf("%#v", key))
+	}
+}
+
+func hashString(s string) uint64 {
+	h := uint64(offset64)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= prime64
+	}
+	return h
+}
+
+func hashUint64(k uint64) uint64 {
+	h := uint64(offset64)
+	for i := 0; i < 8; i++ {
+		h ^= k & 0xff
+		h *= prime64
+		k >>= 8
+	}
+	return h
+}
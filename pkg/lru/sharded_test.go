@@ -0,0 +1,99 @@
+package lru
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSharded(t *testing.T) {
+	s := NewSharded(4, 100)
+	for i := 0; i < 100; i++ {
+		s.Add(i, strconv.Itoa(i))
+	}
+	for i := 0; i < 100; i++ {
+		if v, ok := s.Get(i); !ok || v != strconv.Itoa(i) {
+			t.Fatalf("got %v for key %d; want %d", v, i, i)
+		}
+	}
+
+	for _, tt := range getTests {
+		s.Add(tt.keyToAdd, 1234)
+		val, ok := s.Get(tt.keyToGet)
+		if ok != tt.expectedOk {
+			t.Fatalf("%s: cache hit = %v; want %v", tt.name, ok, !ok)
+		} else if ok && val != 1234 {
+			t.Fatalf("%s expected get to return 1234 but got %v", tt.name, val)
+		}
+	}
+
+	s.Remove(1)
+	if _, ok := s.Get(1); ok {
+		t.Fatal("removed entry should not be returned")
+	}
+
+	s.Clear()
+	if s.Len() != 0 {
+		t.Fatalf("got %d entries; want 0", s.Len())
+	}
+}
+
+func TestShardedLen(t *testing.T) {
+	s := NewSharded(8, 0)
+	for i := 0; i < 1000; i++ {
+		s.Add(fmt.Sprintf("myKey%d", i), 1234)
+	}
+	if s.Len() != 1000 {
+		t.Fatalf("got %d entries; want 1000", s.Len())
+	}
+	for i, c := range s.shards {
+		if c.Len() < 1000/8/2 {
+			t.Fatalf("shard %d is unbalanced with %d entries", i, c.Len())
+		}
+	}
+}
+
+func TestShardedFunc(t *testing.T) {
+	s := NewShardedFunc(2, func() *Cache {
+		return NewTTL(0, time.Minute, 0)
+	})
+	defer s.Close()
+
+	s.Add("myKey", 1234)
+	if s.shard("myKey").TTL != time.Minute {
+		t.Fatal("shard should be created by newCache")
+	}
+}
+
+func benchmarkParallel(b *testing.B, add func(key, value interface{}), get func(key interface{}) (interface{}, bool)) {
+	n := 1000
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("myKey%d", i)
+		add(keys[i], 1234)
+	}
+	b.SetParallelism(8)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if i%10 == 0 {
+				add(keys[i%n], 1234)
+			} else {
+				get(keys[i%n])
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkLRUParallel(b *testing.B) {
+	lru := New(2000)
+	benchmarkParallel(b, lru.Add, lru.Get)
+}
+
+func BenchmarkShardedParallel(b *testing.B) {
+	s := NewSharded(32, 2000)
+	benchmarkParallel(b, s.Add, s.Get)
+}
@@ -0,0 +1,193 @@
+package lru
+
+/*
+   https://arxiv.org/abs/1512.00727
+*/
+
+import (
+	"container/list"
+	"encoding/binary"
+	"sync"
+
+	"github.com/andy2046/gopie/pkg/countminsketch"
+)
+
+// TinyLFU is a W-TinyLFU cache, new entries are added to a small LRU window,
+// the entry evicted from the window is admitted to the main SLRU cache
+// only if it is estimated to be more frequently used than the main cache victim.
+// The access frequency is estimated by a Count-Min Sketch,
+// whose counters are halved after 10 times size of accesses to age the old frequency.
+type TinyLFU struct {
+	windowSize    int
+	protectedSize int
+	mainSize      int
+
+	// window is the LRU queue of the new entries.
+	window *list.List
+	// probation is the LRU queue of the main entries seen once.
+	probation *list.List
+	// protected is the LRU queue of the main entries seen again.
+	protected *list.List
+
+	sketch     *countminsketch.CountMinSketch
+	samples    int
+	sampleSize int
+
+	items map[interface{}]*list.Element
+	mu    sync.Mutex
+}
+
+type tinyLFUEntry struct {
+	entry
+	list *list.List
+}
+
+// NewTinyLFU creates a new TinyLFU holding at most size entries,
+// 1% of which are for the window.
+func NewTinyLFU(size int) *TinyLFU {
+	if size <= 0 {
+		panic("size must be positive int")
+	}
+	windowSize := max(1, size/100)
+	mainSize := size - windowSize
+	sketch, err := countminsketch.New(uint(max(64, size)), 4)
+	if err != nil {
+		panic(err)
+	}
+	c := &TinyLFU{
+		windowSize:    windowSize,
+		mainSize:      mainSize,
+		protectedSize: mainSize * 8 / 10,
+		sketch:        sketch,
+		sampleSize:    10 * size,
+	}
+	c.Clear()
+	return c
+}
+
+// Add adds value to the cache.
+func (c *TinyLFU) Add(key interface{}, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record(key)
+	if e, ok := c.items[key]; ok {
+		e.Value.(*tinyLFUEntry).value = value
+		c.hit(e)
+		return
+	}
+
+	c.push(c.window, &tinyLFUEntry{entry: entry{key: key, value: value}})
+	if c.window.Len() <= c.windowSize {
+		return
+	}
+
+	// the window is full, try to admit its victim to the main cache.
+	candidate := c.window.Back()
+	c.window.Remove(candidate)
+	ce := candidate.Value.(*tinyLFUEntry)
+	if c.probation.Len()+c.protected.Len() < c.mainSize {
+		c.push(c.probation, ce)
+		return
+	}
+
+	victim := c.probation.Back()
+	if victim == nil {
+		victim = c.protected.Back()
+	}
+	if victim == nil || c.frequency(ce.key) <= c.frequency(victim.Value.(*tinyLFUEntry).key) {
+		delete(c.items, ce.key)
+		return
+	}
+	ve := victim.Value.(*tinyLFUEntry)
+	ve.list.Remove(victim)
+	delete(c.items, ve.key)
+	c.push(c.probation, ce)
+}
+
+// Get looks up value by key from the cache.
+func (c *TinyLFU) Get(key interface{}) (value interface{}, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.record(key)
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.hit(e)
+	return e.Value.(*tinyLFUEntry).value, true
+}
+
+// Remove removes the provided key from the cache.
+func (c *TinyLFU) Remove(key interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		e.Value.(*tinyLFUEntry).list.Remove(e)
+		delete(c.items, key)
+	}
+}
+
+// Len returns the number of items in the cache.
+func (c *TinyLFU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.items)
+}
+
+// Clear purges all items from the cache.
+func (c *TinyLFU) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.window, c.probation, c.protected = list.New(), list.New(), list.New()
+	c.items = make(map[interface{}]*list.Element)
+	c.sketch.Reset()
+	c.samples = 0
+}
+
+func (c *TinyLFU) push(l *list.List, te *tinyLFUEntry) {
+	te.list = l
+	c.items[te.key] = l.PushFront(te)
+}
+
+func (c *TinyLFU) hit(e *list.Element) {
+	te := e.Value.(*tinyLFUEntry)
+	switch te.list {
+	case c.window, c.protected:
+		te.list.MoveToFront(e)
+	case c.probation:
+		c.probation.Remove(e)
+		c.push(c.protected, te)
+		if c.protected.Len() > c.protectedSize {
+			// demote the protected victim to probation.
+			d := c.protected.Back()
+			c.protected.Remove(d)
+			c.push(c.probation, d.Value.(*tinyLFUEntry))
+		}
+	}
+}
+
+func (c *TinyLFU) record(key interface{}) {
+	c.sketch.Add(keyBytes(key))
+	c.samples++
+	if c.samples >= c.sampleSize {
+		// halving keeps the relative frequency unlike reset,
+		// so that the admission still favours the frequent entries right after aging.
+		c.sketch.Halve()
+		c.samples /= 2
+	}
+}
+
+func (c *TinyLFU) frequency(key interface{}) uint64 {
+	return c.sketch.Estimate(keyBytes(key))
+}
+
+func keyBytes(key interface{}) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, hashKey(key))
+	return b
+}
@@ -0,0 +1,156 @@
+package lru
+
+/*
+   http://www.vldb.org/conf/1994/P439.PDF
+*/
+
+import (
+	"container/list"
+	"sync"
+)
+
+// TwoQueue is a 2Q cache, the entries seen once are kept in a FIFO queue,
+// only the entries seen again after leaving the FIFO queue are promoted to the LRU queue,
+// so that a scan doesn't evict the frequently used entries.
+type TwoQueue struct {
+	size int
+	// inSize is the maximum number of entries in a1in.
+	inSize int
+	// outSize is the maximum number of keys in a1out.
+	outSize int
+
+	// a1in is the FIFO queue of the entries seen once.
+	a1in *list.List
+	// a1out is the FIFO queue of the keys evicted from a1in.
+	a1out *list.List
+	// am is the LRU queue of the frequently used entries.
+	am *list.List
+
+	items map[interface{}]*list.Element
+	mu    sync.Mutex
+}
+
+type twoQueueEntry struct {
+	entry
+	list *list.List
+}
+
+// NewTwoQueue creates a new TwoQueue holding at most size entries,
+// a quarter of which are for the entries seen once.
+func NewTwoQueue(size int) *TwoQueue {
+	if size <= 0 {
+		panic("size must be positive int")
+	}
+	c := &TwoQueue{
+		size:    size,
+		inSize:  max(1, size/4),
+		outSize: max(1, size/2),
+	}
+	c.Clear()
+	return c
+}
+
+// Add adds value to the cache.
+func (c *TwoQueue) Add(key interface{}, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		qe := e.Value.(*twoQueueEntry)
+		switch qe.list {
+		case c.am:
+			qe.value = value
+			c.am.MoveToFront(e)
+			return
+		case c.a1in:
+			qe.value = value
+			return
+		case c.a1out:
+			c.a1out.Remove(e)
+			delete(c.items, key)
+			c.reclaim()
+			c.push(c.am, key, value)
+			return
+		}
+	}
+
+	c.reclaim()
+	c.push(c.a1in, key, value)
+}
+
+// Get looks up value by key from the cache.
+func (c *TwoQueue) Get(key interface{}) (value interface{}, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	qe := e.Value.(*twoQueueEntry)
+	switch qe.list {
+	case c.am:
+		c.am.MoveToFront(e)
+	case c.a1out:
+		return nil, false
+	}
+	return qe.value, true
+}
+
+// Remove removes the provided key from the cache.
+func (c *TwoQueue) Remove(key interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		e.Value.(*twoQueueEntry).list.Remove(e)
+		delete(c.items, key)
+	}
+}
+
+// Len returns the number of items in the cache.
+func (c *TwoQueue) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.a1in.Len() + c.am.Len()
+}
+
+// Clear purges all items from the cache.
+func (c *TwoQueue) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.a1in, c.a1out, c.am = list.New(), list.New(), list.New()
+	c.items = make(map[interface{}]*list.Element)
+}
+
+func (c *TwoQueue) push(l *list.List, key interface{}, value interface{}) {
+	c.items[key] = l.PushFront(&twoQueueEntry{entry{key: key, value: value}, l})
+}
+
+// reclaim makes room for a new entry if the cache is full.
+func (c *TwoQueue) reclaim() {
+	if c.a1in.Len()+c.am.Len() < c.size {
+		return
+	}
+
+	if c.a1in.Len() >= c.inSize || c.am.Len() == 0 {
+		e := c.a1in.Back()
+		qe := e.Value.(*twoQueueEntry)
+		c.a1in.Remove(e)
+		delete(c.items, qe.key)
+
+		if c.a1out.Len() >= c.outSize {
+			old := c.a1out.Back()
+			c.a1out.Remove(old)
+			delete(c.items, old.Value.(*twoQueueEntry).key)
+		}
+		c.push(c.a1out, qe.key, nil)
+		return
+	}
+
+	e := c.am.Back()
+	c.am.Remove(e)
+	delete(c.items, e.Value.(*twoQueueEntry).key)
+}
@@ -0,0 +1,265 @@
+// Package rendezvous provides a rendezvous (highest random weight) hash implementation.
+package rendezvous
+
+/*
+   https://en.wikipedia.org/wiki/Rendezvous_hashing
+   http://www.snia.org/sites/default/files/SDC15_presentations/dist_sys/Jason_Resch_New_Consistent_Hashings_Rev.pdf
+*/
+
+import (
+	"errors"
+	"hash/crc64"
+	"log"
+	"math"
+	"sort"
+	"sync"
+)
+
+type (
+	// Hash is the hash function.
+	Hash func(key string) uint64
+
+	// Hasher is the interface shared by the consistent hash algorithms,
+	// it is implemented by *rendezvous.Rendezvous, *ringhash.Ring, *jumphash.Hasher
+	// and *jumphash.Memento,
+	// so callers can choose an algorithm by config.
+	Hasher interface {
+		// Get returns the node for the given key.
+		Get(key string) (string, error)
+	}
+
+	// Node is the node with weight.
+	Node struct {
+		Name   string
+		Weight float64
+	}
+
+	// Rendezvous is the data store for weighted nodes.
+	Rendezvous struct {
+		hashFn Hash
+		nodes  []*node
+		index  map[string]int
+
+		mu sync.RWMutex
+	}
+
+	// Config is the config for Rendezvous.
+	Config struct {
+		HashFn Hash
+	}
+
+	// Option applies config to Config.
+	Option = func(*Config) error
+
+	node struct {
+		name   string
+		weight float64
+		hash   uint64
+	}
+
+	score struct {
+		name  string
+		score float64
+	}
+)
+
+var (
+	// tableECMA is the 64-bit Cyclic Redundancy Check (CRC-64) table with the ECMA polynomial.
+	tableECMA = crc64.MakeTable(crc64.ECMA)
+	// ErrNoNode when there is no node added.
+	ErrNoNode = errors.New("no node added")
+	// ErrInvalidWeight when the weight of node is not positive.
+	ErrInvalidWeight = errors.New("weight must be positive")
+	// DefaultConfig is the default config for Rendezvous.
+	DefaultConfig = Config{
+		HashFn: hash,
+	}
+)
+
+func hash(key string) uint64 {
+	return crc64.Checksum([]byte(key), tableECMA)
+}
+
+// New returns a new Rendezvous.
+func New(options ...Option) *Rendezvous {
+	c := DefaultConfig
+	err := setOption(&c, options...)
+	if err != nil {
+		log.Panicf("fail to apply Config -> %v\n", err)
+	}
+
+	return &Rendezvous{
+		hashFn: c.HashFn,
+		index:  map[string]int{},
+	}
+}
+
+// AddNode adds nodes with weight 1.
+func (r *Rendezvous) AddNode(names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, name := range names {
+		r.add(name, 1)
+	}
+}
+
+// AddWeightedNode adds nodes with the given weight,
+// the weight of an existing node is updated.
+func (r *Rendezvous) AddWeightedNode(nodes ...Node) error {
+	for _, n := range nodes {
+		if !(n.Weight > 0) || math.IsInf(n.Weight, 1) {
+			return ErrInvalidWeight
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, n := range nodes {
+		r.add(n.Name, n.Weight)
+	}
+	return nil
+}
+
+// RemoveNode deletes node.
+func (r *Rendezvous) RemoveNode(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.index[name]
+	if !ok {
+		return false
+	}
+
+	last := len(r.nodes) - 1
+	r.nodes[i] = r.nodes[last]
+	r.index[r.nodes[i].name] = i
+	r.nodes[last] = nil
+	r.nodes = r.nodes[:last]
+	delete(r.index, name)
+	return true
+}
+
+// Get returns the node with the highest score for the given key.
+func (r *Rendezvous) Get(key string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.nodes) == 0 {
+		return "", ErrNoNode
+	}
+
+	kh := r.hashFn(key)
+	var (
+		best      string
+		bestScore = math.Inf(-1)
+	)
+	for _, n := range r.nodes {
+		s := n.score(kh)
+		if s > bestScore || (s == bestScore && n.name < best) {
+			best, bestScore = n.name, s
+		}
+	}
+	return best, nil
+}
+
+// GetN returns at most n nodes ordered by score descendingly for the given key,
+// the first node is the same as the one returned by Get.
+func (r *Rendezvous) GetN(key string, n int) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.nodes) == 0 {
+		return nil, ErrNoNode
+	}
+	if n <= 0 {
+		return []string{}, nil
+	}
+	if n > len(r.nodes) {
+		n = len(r.nodes)
+	}
+
+	kh := r.hashFn(key)
+	scores := make([]score, len(r.nodes))
+	for i, nd := range r.nodes {
+		scores[i] = score{nd.name, nd.score(kh)}
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].score == scores[j].score {
+			return scores[i].name < scores[j].name
+		}
+		return scores[i].score > scores[j].score
+	})
+
+	names := make([]string, n)
+	for i := range names {
+		names[i] = scores[i].name
+	}
+	return names, nil
+}
+
+// Nodes returns the list of nodes.
+func (r *Rendezvous) Nodes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	nodes := make([]string, len(r.nodes))
+	for i, n := range r.nodes {
+		nodes[i] = n.name
+	}
+	return nodes
+}
+
+// Weights returns the weights of all the nodes.
+func (r *Rendezvous) Weights() map[string]float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	weights := make(map[string]float64, len(r.nodes))
+	for _, n := range r.nodes {
+		weights[n.name] = n.weight
+	}
+	return weights
+}
+
+func (r *Rendezvous) add(name string, weight float64) {
+	if i, ok := r.index[name]; ok {
+		r.nodes[i].weight = weight
+		return
+	}
+	r.index[name] = len(r.nodes)
+	r.nodes = append(r.nodes, &node{
+		name:   name,
+		weight: weight,
+		hash:   r.hashFn(name),
+	})
+}
+
+// score uses the logarithmic method, which is -weight / ln(h),
+// h is the combined hash of key and node mapped to (0, 1).
+func (n *node) score(keyHash uint64) float64 {
+	h := mix(keyHash ^ n.hash)
+	// use the top 53 bits, offset by half a step to exclude 0 and 1.
+	f := (float64(h>>11) + 0.5) / (1 << 53)
+	return -n.weight / math.Log(f)
+}
+
+// mix is the finalizer of SplitMix64.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+func setOption(c *Config, options ...func(*Config) error) error {
+	for _, opt := range options {
+		if err := opt(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package rendezvous_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/andy2046/gopie/pkg/jumphash"
+	. "github.com/andy2046/gopie/pkg/rendezvous"
+	"github.com/andy2046/gopie/pkg/ringhash"
+)
+
+var (
+	_ Hasher = (*Rendezvous)(nil)
+	_ Hasher = (*ringhash.Ring)(nil)
+	_ Hasher = (*jumphash.Hasher)(nil)
+	_ Hasher = (*jumphash.Memento)(nil)
+)
+
+func TestGetNoNode(t *testing.T) {
+	r := New()
+	if _, err := r.Get("key"); err != ErrNoNode {
+		t.Fatalf("expected ErrNoNode, got %v", err)
+	}
+	if _, err := r.GetN("key", 2); err != ErrNoNode {
+		t.Fatalf("expected ErrNoNode, got %v", err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	r := New()
+	r.AddNode("10.0.0.1:80", "10.0.0.2:80", "10.0.0.3:80")
+
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		n1, err := r.Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n2, _ := r.Get(key)
+		if n1 != n2 {
+			t.Fatalf("inconsistent node for key %s, %s != %s", key, n1, n2)
+		}
+		top, _ := r.GetN(key, 3)
+		if len(top) != 3 || top[0] != n1 {
+			t.Fatalf("GetN %v should start with %s", top, n1)
+		}
+	}
+}
+
+func TestGetN(t *testing.T) {
+	r := New()
+	r.AddNode("a", "b", "c")
+
+	nodes, err := r.GetN("key", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 3 {
+		t.Fatalf("expected 3 nodes, got %v", nodes)
+	}
+	seen := map[string]bool{}
+	for _, n := range nodes {
+		if seen[n] {
+			t.Fatalf("duplicate node %s in %v", n, nodes)
+		}
+		seen[n] = true
+	}
+
+	if nodes, _ = r.GetN("key", 0); len(nodes) != 0 {
+		t.Fatalf("expected no node, got %v", nodes)
+	}
+}
+
+func TestRemoveNode(t *testing.T) {
+	r := New()
+	r.AddNode("a", "b", "c", "d")
+
+	before := map[string]string{}
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		before[key], _ = r.Get(key)
+	}
+
+	if !r.RemoveNode("b") {
+		t.Fatal("remove not working")
+	}
+	if r.RemoveNode("b") {
+		t.Fatal("node b should be removed already")
+	}
+
+	for key, n := range before {
+		got, _ := r.Get(key)
+		if n != "b" && got != n {
+			t.Fatalf("key %s moved from %s to %s", key, n, got)
+		}
+		if got == "b" {
+			t.Fatalf("key %s mapped to removed node", key)
+		}
+	}
+}
+
+func TestWeight(t *testing.T) {
+	r := New()
+	if err := r.AddWeightedNode(Node{"a", 0}); err != ErrInvalidWeight {
+		t.Fatalf("expected ErrInvalidWeight, got %v", err)
+	}
+	err := r.AddWeightedNode(Node{"a", 1}, Node{"b", 2}, Node{"c", 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	total := 30000
+	counts := map[string]int{}
+	for i := 0; i < total; i++ {
+		n, _ := r.Get(strconv.Itoa(i))
+		counts[n]++
+	}
+	t.Log(counts)
+
+	for n, w := range r.Weights() {
+		expected := float64(total) * w / 4
+		if math.Abs(float64(counts[n])-expected)/expected > 0.05 {
+			t.Fatalf("node %s got %d keys, expected about %.0f", n, counts[n], expected)
+		}
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	r := New()
+	for i := 0; i < 10; i++ {
+		r.AddNode("10.0.0." + strconv.Itoa(i) + ":80")
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Get(strconv.Itoa(i))
+	}
+}
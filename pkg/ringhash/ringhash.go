@@ -131,6 +131,11 @@ func (r *Ring) GetNode(key string) (string, error) {
 	return r.hashKeyMap[r.hashes[idx]], nil
 }
 
+// Get is an alias of GetNode.
+func (r *Ring) Get(key string) (string, error) {
+	return r.GetNode(key)
+}
+
 // GetLeastNode uses consistent hashing with bounded loads to get the least loaded node.
 func (r *Ring) GetLeastNode(key string) (string, error) {
 	r.mu.RLock()
@@ -0,0 +1,125 @@
+package subset
+
+import (
+	"math"
+	"strconv"
+	"sync"
+
+	"github.com/andy2046/gopie/pkg/rendezvous"
+)
+
+type (
+	// Backend is the backend with weight.
+	Backend = rendezvous.Node
+
+	// Dynamic implements deterministic subsetting with rendezvous hashing,
+	// each client picks the subsetSize backends with the highest scores for its clientID,
+	// so that a subset changes by at most one backend when a backend joins or leaves.
+	// Unlike Subset, the number of backends doesn't have to be a multiple of subsetSize,
+	// while the connections are less evenly spread across backends, use Report to check it.
+	Dynamic struct {
+		subsetSize int
+		r          *rendezvous.Rendezvous
+
+		mu sync.RWMutex
+	}
+
+	// Report is the per-backend connection balance across all clients.
+	Report struct {
+		// Connections is the number of clients connected to each backend.
+		Connections map[string]int
+		// Min is the minimum number of connections per backend.
+		Min int
+		// Max is the maximum number of connections per backend.
+		Max int
+		// Mean is the average number of connections per backend.
+		Mean float64
+	}
+)
+
+// NewDynamic returns a new Dynamic with the given backends of weight 1.
+func NewDynamic(subsetSize int, backends ...string) *Dynamic {
+	if subsetSize <= 0 {
+		panic("subsetSize must be positive int")
+	}
+	r := rendezvous.New()
+	r.AddNode(backends...)
+	return &Dynamic{
+		subsetSize: subsetSize,
+		r:          r,
+	}
+}
+
+// AddBackend adds backends with the given weight,
+// the weight of an existing backend is updated.
+func (d *Dynamic) AddBackend(backends ...Backend) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.r.AddWeightedNode(backends...)
+}
+
+// RemoveBackend deletes backend.
+func (d *Dynamic) RemoveBackend(backend string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.r.RemoveNode(backend)
+}
+
+// Backends returns the list of backends.
+func (d *Dynamic) Backends() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.r.Nodes()
+}
+
+// Subset returns a subset of backends with size subsetSize for the given clientID,
+// all the backends are returned if there are not more than subsetSize backends.
+func (d *Dynamic) Subset(clientID int) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	backends, err := d.r.GetN(strconv.Itoa(clientID), d.subsetSize)
+	if err != nil {
+		return []string{}
+	}
+	return backends
+}
+
+// Report returns the per-backend connection balance for clientID in [0, clientCount).
+func (d *Dynamic) Report(clientCount int) Report {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	rp := Report{Connections: map[string]int{}}
+	nodes := d.r.Nodes()
+	if len(nodes) == 0 {
+		return rp
+	}
+	for _, n := range nodes {
+		rp.Connections[n] = 0
+	}
+
+	for i := 0; i < clientCount; i++ {
+		backends, _ := d.r.GetN(strconv.Itoa(i), d.subsetSize)
+		for _, b := range backends {
+			rp.Connections[b]++
+		}
+	}
+
+	rp.Min, rp.Max = math.MaxInt32, 0
+	total := 0
+	for _, c := range rp.Connections {
+		total += c
+		if c < rp.Min {
+			rp.Min = c
+		}
+		if c > rp.Max {
+			rp.Max = c
+		}
+	}
+	rp.Mean = float64(total) / float64(len(rp.Connections))
+	return rp
+}
@@ -0,0 +1,105 @@
+package subset
+
+import (
+	"strconv"
+	"testing"
+)
+
+func backendList(n int) []string {
+	backends := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		backends = append(backends, strconv.Itoa(i))
+	}
+	return backends
+}
+
+func diff(a, b []string) int {
+	m := map[string]bool{}
+	for _, s := range a {
+		m[s] = true
+	}
+	n := 0
+	for _, s := range b {
+		if !m[s] {
+			n++
+		}
+	}
+	return n
+}
+
+func TestDynamicSubset(t *testing.T) {
+	d := NewDynamic(10, backendList(33)...)
+
+	for i := 0; i < 100; i++ {
+		s := d.Subset(i)
+		if len(s) != 10 {
+			t.Fatalf("expected subset size 10, got %v", s)
+		}
+		if diff(s, d.Subset(i)) != 0 {
+			t.Fatalf("subset for client %d is not deterministic", i)
+		}
+	}
+
+	small := NewDynamic(10, "a", "b")
+	if s := small.Subset(0); len(s) != 2 {
+		t.Fatalf("expected all backends, got %v", s)
+	}
+	if s := NewDynamic(10).Subset(0); len(s) != 0 {
+		t.Fatalf("expected no backend, got %v", s)
+	}
+}
+
+func TestDynamicChurn(t *testing.T) {
+	clientSize := 300
+	d := NewDynamic(10, backendList(300)...)
+
+	before := make([][]string, clientSize)
+	for i := range before {
+		before[i] = d.Subset(i)
+	}
+
+	d.RemoveBackend("42")
+	for i := range before {
+		after := d.Subset(i)
+		if n := diff(before[i], after); n > 1 {
+			t.Fatalf("client %d subset changed by %d backends on remove", i, n)
+		}
+		before[i] = after
+	}
+
+	d.AddBackend(Backend{Name: "300", Weight: 1})
+	for i := range before {
+		if n := diff(before[i], d.Subset(i)); n > 1 {
+			t.Fatalf("client %d subset changed by %d backends on add", i, n)
+		}
+	}
+}
+
+func TestDynamicWeight(t *testing.T) {
+	d := NewDynamic(2, backendList(20)...)
+	if err := d.AddBackend(Backend{Name: "heavy", Weight: 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	rp := d.Report(1000)
+	t.Logf("min -> %d max -> %d mean -> %.2f", rp.Min, rp.Max, rp.Mean)
+	if heavy := rp.Connections["heavy"]; heavy != rp.Max {
+		t.Fatalf("heavy backend should have the most connections, got %d < %d", heavy, rp.Max)
+	}
+}
+
+func TestDynamicReport(t *testing.T) {
+	clientSize := 300
+	d := NewDynamic(10, backendList(299)...)
+
+	rp := d.Report(clientSize)
+	total := 0
+	for _, c := range rp.Connections {
+		total += c
+	}
+	if total != clientSize*10 || len(rp.Connections) != 299 {
+		t.Fatalf("expected %d connections to 299 backends, got %d to %d",
+			clientSize*10, total, len(rp.Connections))
+	}
+	t.Logf("min -> %d max -> %d mean -> %.2f", rp.Min, rp.Max, rp.Mean)
+}